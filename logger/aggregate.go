@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Maximum number of ids which are listed in an aggregation summary
+const aggregateMaxIds = 10
+
+// Collects identical entries within one aggregation window
+type aggregate struct {
+	first Container // First entry of the window, emitted unchanged if no further entry arrives
+	count int       // Number of entries which share the aggregation key
+	ids   []string  // Ids of the aggregated entries (at most aggregateMaxIds)
+}
+
+// Human readable labels of the fields which can be used as aggregation keys
+var aggregateKeyLabels = map[LogFormat]string{
+	FORMAT_STATUS:   "Status",
+	FORMAT_PRE_TEXT: "PreText",
+	FORMAT_ID:       "Id",
+	FORMAT_SOURCE:   "Source",
+	FORMAT_INFO:     "Info",
+	FORMAT_DATA:     "Data",
+	FORMAT_ERROR:    "Error",
+}
+
+// Reports whether entries shall be aggregated.
+//
+// Returns:
+//   - bool: true if an aggregation window and at least one aggregation key is configured
+func (l *Logger) aggregationEnabled() bool {
+	return l.Options.AggregateWindow > 0 && len(l.Options.AggregateKeys) > 0
+}
+
+// Returns the value of a field which can be used as aggregation key.
+//
+// The error is resolved like in the output (see getError), so Container.Err is part of the key.
+//
+// Parameters:
+//   - item: LogFormat - the field to read
+//   - c: *Container - the log entry container
+//
+// Returns:
+//   - string: the value of the field, or an empty string if the field is not supported as key
+func (l *Logger) aggregateKeyValue(item LogFormat, c *Container) string {
+	switch item {
+	case FORMAT_STATUS:
+		return logStatustoString[c.Status]
+	case FORMAT_PRE_TEXT:
+		return c.PreText
+	case FORMAT_ID:
+		return c.Id
+	case FORMAT_SOURCE:
+		return c.Source
+	case FORMAT_INFO:
+		return c.Info
+	case FORMAT_DATA:
+		return c.Data
+	case FORMAT_ERROR:
+		// The error as it is rendered, so entries with different typed errors are not merged
+		return l.getError(c)
+	}
+	return ""
+}
+
+// Adds a log entry to the aggregate identified by the configured aggregation keys.
+//
+// Parameters:
+//   - c: Container - the log entry container
+func (l *Logger) aggregate(c Container) {
+	values := make([]string, 0, len(l.Options.AggregateKeys))
+	for _, item := range l.Options.AggregateKeys {
+		values = append(values, l.aggregateKeyValue(item, &c))
+	}
+	key := strings.Join(values, "\x00")
+
+	if l.aggregates == nil {
+		l.aggregates = make(map[string]*aggregate)
+	}
+
+	a, ok := l.aggregates[key]
+	if !ok {
+		a = &aggregate{first: c}
+		l.aggregates[key] = a
+		l.aggregateOrder = append(l.aggregateOrder, key)
+	}
+
+	a.count++
+	if c.Id != "" && len(a.ids) < aggregateMaxIds {
+		a.ids = append(a.ids, c.Id)
+	}
+}
+
+// Writes all collected aggregates and starts a new aggregation window.
+//
+// An aggregate holding a single entry is written unchanged. Otherwise a summary entry is written
+// which carries the status and pre text of the first entry and describes the aggregate in its info field.
+func (l *Logger) flushAggregates() {
+	for _, key := range l.aggregateOrder {
+		a := l.aggregates[key]
+		if a.count == 1 {
+			l.writeEntry(a.first)
+			continue
+		}
+
+		l.writeEntry(Container{
+			Status:    a.first.Status,
+			PreText:   a.first.PreText,
+			Info:      l.aggregateSummary(a),
+			Timestamp: generateTimestamp(),
+		})
+	}
+
+	l.aggregates = nil
+	l.aggregateOrder = nil
+}
+
+// Describes an aggregate in a single line.
+//
+// Parameters:
+//   - a: *aggregate - the aggregate to describe
+//
+// Returns:
+//   - string: the summary
+//
+// Example:
+//
+//	// Output: Error timeout, Source handler/user occurred 312 times (ids: 5f322ac4ba, 5f322ac4bb, ... +310 more)
+func (l *Logger) aggregateSummary(a *aggregate) string {
+	var builder strings.Builder
+
+	parts := make([]string, 0, len(l.Options.AggregateKeys))
+	for _, item := range l.Options.AggregateKeys {
+		if label := aggregateKeyLabels[item]; label != "" {
+			parts = append(parts, label+" "+l.aggregateKeyValue(item, &a.first))
+		}
+	}
+	builder.WriteString(strings.Join(parts, ", "))
+	builder.WriteString(fmt.Sprintf(" occurred %d times", a.count))
+
+	if len(a.ids) > 0 {
+		builder.WriteString(" (ids: " + strings.Join(a.ids, ", "))
+		if more := a.count - len(a.ids); more > 0 && len(a.ids) == aggregateMaxIds {
+			builder.WriteString(fmt.Sprintf(", ... +%d more", more))
+		}
+		builder.WriteString(")")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAggregateSummary(t *testing.T) {
+	l := &Logger{
+		Options: Options{
+			AggregateWindow: time.Second,
+			AggregateKeys:   []LogFormat{FORMAT_ERROR, FORMAT_SOURCE},
+		},
+	}
+
+	for i := 0; i < 12; i++ {
+		l.aggregate(Container{Status: STATUS_ERROR, Id: fmt.Sprintf("id%d", i), Source: "handler/user", Error: "timeout"})
+	}
+	l.aggregate(Container{Status: STATUS_ERROR, Id: "other", Source: "handler/order", Error: "timeout"})
+
+	if len(l.aggregateOrder) != 2 {
+		t.Fatalf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 2, len(l.aggregateOrder))
+	}
+
+	expected := "Error timeout, Source handler/user occurred 12 times (ids: id0, id1, id2, id3, id4, id5, id6, id7, id8, id9, ... +2 more)"
+	actual := l.aggregateSummary(l.aggregates[l.aggregateOrder[0]])
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	if count := l.aggregates[l.aggregateOrder[1]].count; count != 1 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 1, count)
+	}
+}
+
+func TestAggregateKeyUsesErr(t *testing.T) {
+	l := &Logger{
+		Options: Options{
+			AggregateWindow: time.Second,
+			AggregateKeys:   []LogFormat{FORMAT_ERROR},
+		},
+	}
+
+	// Entries with different typed errors are separate aggregates
+	for i := 0; i < 2; i++ {
+		l.aggregate(Container{Status: STATUS_ERROR, Err: errors.New("timeout")})
+		l.aggregate(Container{Status: STATUS_ERROR, Err: errors.New("connection refused")})
+	}
+
+	if len(l.aggregateOrder) != 2 {
+		t.Fatalf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 2, len(l.aggregateOrder))
+	}
+
+	for i, expected := range []string{"Error timeout occurred 2 times", "Error connection refused occurred 2 times"} {
+		if actual := l.aggregateSummary(l.aggregates[l.aggregateOrder[i]]); actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+	}
+}
+
+func TestAggregateKeysValidated(t *testing.T) {
+	// FORMAT_TIMESTAMP differs per entry and is not a supported aggregation key
+	_, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		AggregateWindow: time.Second,
+		AggregateKeys:   []LogFormat{FORMAT_ERROR, FORMAT_TIMESTAMP},
+	}, Container{})
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrInvalidFormat, err)
+	}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"io"
+	"os"
+)
+
+// ANSI escape sequences used to color the status on STDOUT
+const (
+	ansiReset  = "\033[0m"
+	ansiDim    = "\033[2m"
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+)
+
+// ANSI colors of the statuses, statuses without an entry keep the default color
+var statusColors = map[LogStatus]string{
+	STATUS_TRACE: ansiDim,
+	STATUS_WARN:  ansiYellow,
+	STATUS_ERROR: ansiRed,
+	STATUS_FATAL: ansiRed,
+}
+
+// Wraps the status text in the ANSI color of the status.
+//
+// Parameters:
+//   - status: LogStatus - the status which defines the color
+//   - text: string - the text to color
+//
+// Returns:
+//   - string: the colored text, or the unchanged text if the status has no color
+func colorizeStatus(status LogStatus, text string) string {
+	color := statusColors[status]
+	if color == "" || text == "" {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// Reports whether a writer is an interactive terminal, replaceable in tests
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Reports whether the STDOUT output shall be colored.
+//
+// Coloring requires Options.ColorStdout and is disabled if the NO_COLOR environment variable is set
+// (see https://no-color.org) or if STDOUT is not a terminal, e.g. when it is piped to a file.
+// The decision is made once on first use.
+//
+// Returns:
+//   - bool: true if the status shall be colored on STDOUT
+func (l *Logger) colorStdout() bool {
+	l.colorOnce.Do(func() {
+		l.color = l.Options.ColorStdout && os.Getenv("NO_COLOR") == "" && isTerminal(l.stdout())
+	})
+	return l.color
+}
@@ -0,0 +1,35 @@
+package logger
+
+import "context"
+
+// Logs a message based on the provided container and the context it belongs to.
+//
+// If Container.Id is empty, it is populated with the request/trace id stored in the context under
+// Options.ContextIdKey (if the value is a non-empty string). If Options.SkipCanceledContext is set and
+// the context is already canceled or past its deadline, the entry is skipped. Otherwise it behaves like Entry.
+//
+// Parameters:
+//   - ctx: context.Context - the context of the operation which logs
+//   - c: Container - the log entry container containing the log message and metadata
+//
+// Example:
+//
+//	type ctxKey string
+//	appLogger.Options.ContextIdKey = ctxKey("request_id")
+//
+//	ctx := context.WithValue(r.Context(), ctxKey("request_id"), "5f322ac4ba")
+//	appLogger.EntryCtx(ctx, logger.Container{Status: logger.STATUS_INFO, Info: "user created"})
+//	// the entry is logged with Id "5f322ac4ba"
+func (l *Logger) EntryCtx(ctx context.Context, c Container) {
+	if l.Options.SkipCanceledContext && ctx.Err() != nil {
+		return
+	}
+
+	if c.Id == "" && l.Options.ContextIdKey != nil {
+		if id, ok := ctx.Value(l.Options.ContextIdKey).(string); ok {
+			c.Id = id
+		}
+	}
+
+	l.entry(c, 1)
+}
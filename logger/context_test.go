@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type contextKey string
+
+func TestEntryCtx(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_ID, FORMAT_INFO}, Options{
+		OutputToStdout:      true,
+		Stdout:              &capturedOutput,
+		ContextIdKey:        contextKey("request_id"),
+		SkipCanceledContext: true,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	ctx := context.WithValue(context.Background(), contextKey("request_id"), "5f322ac4ba")
+	logger.EntryCtx(ctx, Container{Status: STATUS_INFO, Info: "from context"})
+	logger.EntryCtx(ctx, Container{Status: STATUS_INFO, Id: "explicit", Info: "keeps id"})
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	logger.EntryCtx(canceled, Container{Status: STATUS_INFO, Info: "canceled"})
+
+	logger.Close()
+
+	expected := "started\n5f322ac4ba from context\nexplicit keeps id\n"
+	if actual := capturedOutput.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
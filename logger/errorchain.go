@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+)
+
+// Maximum number of layers rendered by errorChain, protects against extremely deep or cyclic chains
+const maxErrorChainDepth = 32
+
+// Returns the messages of the layers of an error chain, outermost first.
+//
+// The chain is followed with errors.Unwrap. Wrapping errors usually repeat the message of the wrapped
+// error (e.g. fmt.Errorf("read config: %w", err)), so that suffix is removed and every layer only
+// contributes its own context. Layers whose message does not include the wrapped message (e.g. custom
+// error types) are kept completely, which makes their causes visible. The chain is cut after
+// maxErrorChainDepth layers and "..." is appended.
+//
+// Parameters:
+//   - err: error - the error to expand
+//
+// Returns:
+//   - []string: the messages of the layers, nil if err is nil
+//
+// Example:
+//
+//	err := fmt.Errorf("load user: %w", fmt.Errorf("query: %w", sql.ErrNoRows))
+//	chain := errorChain(err)
+//	// chain will be []string{"load user", "query", "sql: no rows in result set"}
+func errorChain(err error) []string {
+	var chain []string
+
+	for depth := 0; err != nil; depth++ {
+		if depth == maxErrorChainDepth {
+			return append(chain, "...")
+		}
+
+		message := err.Error()
+		inner := errors.Unwrap(err)
+		if inner != nil {
+			if trimmed, ok := strings.CutSuffix(message, ": "+inner.Error()); ok {
+				message = trimmed
+			}
+		}
+
+		chain = append(chain, message)
+		err = inner
+	}
+
+	return chain
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// Error type which does not repeat the message of its cause
+type queryError struct {
+	cause error
+}
+
+func (e *queryError) Error() string { return "query failed" }
+func (e *queryError) Unwrap() error { return e.cause }
+
+// Error which wraps itself
+type cyclicError struct{}
+
+func (e *cyclicError) Error() string { return "cyclic" }
+func (e *cyclicError) Unwrap() error { return e }
+
+func TestErrorChain(t *testing.T) {
+	err := fmt.Errorf("load user: %w", &queryError{cause: errors.New("connection refused")})
+
+	expected := []string{"load user", "query failed", "connection refused"}
+	if actual := errorChain(err); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_INFO, FORMAT_ERROR},
+		Options: Options{ExpandErrorChain: true},
+	}
+	c := Container{Info: "request failed", Err: err}
+
+	expectedText := "request failed load user: query failed: connection refused"
+	if actual := l.FormatEntry(c); expectedText != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expectedText, actual)
+	}
+
+	l.Options.OutputFormat = OUTPUT_JSON
+	expectedText = `{"info":"request failed","error":"load user: query failed: connection refused","error_chain":["load user","query failed","connection refused"]}`
+	if actual := l.FormatEntry(c); expectedText != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expectedText, actual)
+	}
+}
+
+func TestErrorChainDepthCap(t *testing.T) {
+	chain := errorChain(&cyclicError{})
+
+	if expected, actual := maxErrorChainDepth+1, len(chain); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+	if expected, actual := "...", chain[len(chain)-1]; expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the logger. They are wrapped with additional context, so use errors.Is to check for them.
+var (
+	ErrInvalidSeverity        = errors.New("invalid log status")                    // A LogStatus outside the defined STATUS_* range was used
+	ErrInvalidFormat          = errors.New("invalid format item")                   // A LogFormat outside the defined FORMAT_* range was used
+	ErrFolderNotWritable      = errors.New("output folder is not writable")         // The output folder exists but files cannot be created in it
+	ErrOutputPathNotDirectory = errors.New("output path is not a directory")        // The output folder path points to something other than a directory
+	ErrLogQueueFull           = errors.New("log queue is full")                     // An entry was dropped because the log channel (or the webhook queue) was full
+	ErrLoggerClosed           = errors.New("logger is closed")                      // The logger was already closed
+	ErrSyslogUnavailable      = errors.New("syslog is not available")               // Syslog is not supported on this platform
+	ErrDuplicateFormat        = errors.New("format item configured more than once") // Passed to Options.ErrorHandler as warning, the item is rendered repeatedly
+)
+
+// Reports an internal error of the logger, e.g. a failed write to the log file.
+//
+// The error is passed to Options.ErrorHandler if it is set, otherwise it is printed to STDOUT.
+//
+// Parameters:
+//   - action: string - the failed action e.g. "open log file"
+//   - err: error - the error which occurred
+func (l *Logger) reportError(action string, err error) {
+	if l.Options.ErrorHandler != nil {
+		l.Options.ErrorHandler(fmt.Errorf("failed to %s: %w", action, err))
+		return
+	}
+	fmt.Printf("Failed to %s: %v\n", action, err)
+}
@@ -0,0 +1,438 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Writes the log message to a log file.
+//
+// It formats the log file name as "YYYY_MM_DD.log" (or "prefix_YYYY_MM_DD.log", see Options.FilePrefix) based on the log event timestamp.
+// The log file is opened in append mode and created if it doesn't exist. The file handle is
+// cached on the logger and only reopened when the file name changes or after a rotation.
+// If Options.MaxFileSizeBytes is set and the message would exceed it, the file is rotated first.
+// When a new day starts, log files outside of Options.MaxRetentionDays are removed.
+// The log message is written to the file, terminated by Options.LineEnding. While processLogs drains a batch of
+// entries, the messages are buffered and written with a single write after the batch (see Options.FlushInterval
+// to buffer them even longer).
+//
+// Parameters:
+//   - message: string - the log message to write
+//   - c: *Container - the log entry container
+func (l *Logger) writeLogToFile(message string, c *Container) {
+	l.fileMu.Lock()
+	defer l.fileMu.Unlock()
+
+	// Format the log file name as YYYY_MM_DD.log based on the log event timestamp
+	// This means that for each day a new log file will be created
+	logFileName := l.logFileName(c.Timestamp)
+
+	if l.file == nil || l.fileName != logFileName {
+		dayChanged := l.fileName != ""
+
+		if err := l.openLogFile(logFileName); err != nil {
+			l.writeErr = err
+			l.reportError("open log file", err)
+			return
+		}
+
+		// A new day has started, so older files may have left the retention period
+		if dayChanged {
+			l.removeExpiredLogFiles(c.Timestamp)
+		}
+	}
+
+	// Rotate the file if the message would exceed the maximum file size
+	line := l.terminateLine(message)
+	lineSize := int64(len(line))
+	if limit := l.Options.MaxFileSizeBytes; limit > 0 && l.fileSize > 0 && l.fileSize+lineSize > limit {
+		if err := l.rotateBySize(); err != nil {
+			l.writeErr = err
+			l.reportError("rotate log file", err)
+			return
+		}
+	}
+
+	// Write the log message to the buffer, it is written to the file right away unless a batch is
+	// being drained or writes are flushed periodically
+	n, err := io.WriteString(l.fileBuf, line)
+	l.fileSize += int64(n)
+	if err == nil && !l.batching && l.Options.FlushInterval <= 0 {
+		err = l.fileBuf.Flush()
+	}
+	if err != nil {
+		l.writeErr = err
+		l.reportError("write to log file", err)
+	}
+}
+
+// Returns the path of the log file for the given timestamp.
+//
+// Parameters:
+//   - timestamp: time.Time - the timestamp which defines the log period
+//
+// Returns:
+//   - string: the path of the log file e.g. "/var/log/app/2023_06_01.log" or "/var/log/app/access_2023_06_01.log"
+func (l *Logger) logFileName(timestamp time.Time) string {
+	return filepath.Join(l.Options.OutputFolderPath, l.filePrefix()+timestamp.Format("2006_01_02")+".log")
+}
+
+// Returns the prefix of the log file names including the separator.
+//
+// Returns:
+//   - string: e.g. "access_" for Options.FilePrefix "access", or "" if no prefix is set
+func (l *Logger) filePrefix() string {
+	if l.Options.FilePrefix == "" {
+		return ""
+	}
+	return l.Options.FilePrefix + "_"
+}
+
+// Replaces the cached log file handle with a handle to the given file.
+//
+// The log file is opened in append mode and created if it doesn't exist. The caller must hold fileMu.
+//
+// Parameters:
+//   - name: string - the path of the log file to open
+//
+// Returns:
+//   - error: an error if the previous handle could not be closed or the file could not be opened
+func (l *Logger) openLogFile(name string) error {
+	if err := l.closeLogFile(); err != nil {
+		return err
+	}
+
+	// Open the log file in append mode, create if it doesn't exist
+	file, err := l.fileSystem().OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, l.fileMode())
+	if err != nil {
+		return err
+	}
+
+	// Determine the current size once, further writes are tracked without calling stat
+	var size int64
+	if info, err := l.fileSystem().Stat(name); err == nil {
+		size = info.Size()
+	}
+
+	l.file = file
+	l.fileName = name
+	l.fileSize = size
+	l.fileBuf = bufio.NewWriter(file)
+
+	l.updateCurrentLogLink()
+
+	return nil
+}
+
+// Name of the link to the active log file, see Options.CurrentLogLink
+const currentLogLinkName = "current.log"
+
+// Points "current.log" (or "prefix_current.log", see Options.FilePrefix) in the output folder to the active log file.
+//
+// The link is created as a relative symlink, so the folder can be moved. On platforms without symlinks
+// a hard link is created instead. The new link is created under a temporary name and renamed over the
+// old one, so "current.log" never disappears. Does nothing if Options.CurrentLogLink is not set.
+// The caller must hold fileMu.
+func (l *Logger) updateCurrentLogLink() {
+	if !l.Options.CurrentLogLink {
+		return
+	}
+
+	fsys := l.fileSystem()
+	link := filepath.Join(l.Options.OutputFolderPath, l.filePrefix()+currentLogLinkName)
+	tmp := link + ".tmp"
+
+	fsys.Remove(tmp)
+	err := fsys.Symlink(filepath.Base(l.fileName), tmp)
+	if err != nil {
+		err = fsys.Link(l.fileName, tmp)
+	}
+	if err == nil {
+		err = fsys.Rename(tmp, link)
+	}
+	if err != nil {
+		l.reportError("update "+currentLogLinkName, err)
+	}
+}
+
+// Writes the buffered log messages to the log file.
+//
+// Does nothing if no log file is open.
+func (l *Logger) flushLogFile() {
+	l.fileMu.Lock()
+	defer l.fileMu.Unlock()
+
+	if l.fileBuf == nil {
+		return
+	}
+	if err := l.fileBuf.Flush(); err != nil {
+		l.writeErr = err
+		l.reportError("flush log file", err)
+	}
+}
+
+// Closes the cached log file handle, if any. Buffered log messages are written first. The caller must hold fileMu.
+//
+// Returns:
+//   - error: an error if the buffered messages could not be written or the handle could not be closed
+func (l *Logger) closeLogFile() error {
+	if l.file == nil {
+		return nil
+	}
+
+	var err error
+	if l.fileBuf != nil {
+		err = l.fileBuf.Flush()
+		l.fileBuf = nil
+	}
+
+	err = errors.Join(err, l.file.Close())
+	l.file = nil
+	l.fileName = ""
+	l.fileSize = 0
+
+	return err
+}
+
+// Closes the current log file and reopens a fresh handle for the current period.
+//
+// This allows external tooling (e.g. logrotate) to move the log file away and let the logger continue
+// writing into a newly created file without waiting for the date to change. Rotate is safe to call
+// concurrently with logging; writes are blocked until the new file is opened.
+// If file output is disabled, Rotate only releases a possibly cached handle.
+//
+// Returns:
+//   - error: ErrLoggerClosed if the logger was closed, or an error if the current file could not be closed
+//     or the new file could not be opened
+func (l *Logger) Rotate() error {
+	l.closeMu.RLock()
+	defer l.closeMu.RUnlock()
+
+	if l.closed.Load() {
+		return ErrLoggerClosed
+	}
+
+	l.fileMu.Lock()
+	defer l.fileMu.Unlock()
+
+	if !l.Options.OutputToFile {
+		return l.closeLogFile()
+	}
+
+	return l.openLogFile(l.logFileName(l.normalizeTimestamp(generateTimestamp())))
+}
+
+// Moves the current log file to the next free numbered name and reopens the log file.
+//
+// The current file "YYYY_MM_DD.log" (with the optional prefix) is renamed to "YYYY_MM_DD.1.log", "YYYY_MM_DD.2.log", etc.
+// (the first index which is not taken yet), so lower numbers hold older entries.
+// The caller must hold fileMu.
+//
+// Returns:
+//   - error: an error if the file could not be closed, renamed or reopened
+func (l *Logger) rotateBySize() error {
+	name := l.fileName
+	if err := l.closeLogFile(); err != nil {
+		return err
+	}
+
+	fsys := l.fileSystem()
+	base := strings.TrimSuffix(name, ".log")
+	for i := 1; ; i++ {
+		rotated := fmt.Sprintf("%s.%d.log", base, i)
+		if _, err := fsys.Stat(rotated); os.IsNotExist(err) {
+			if err := fsys.Rename(name, rotated); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	return l.openLogFile(name)
+}
+
+// Matches the names of log files and captures their date and rotation number e.g. "2023_06_01.log" or "2023_06_01.2.log"
+var logFileNamePattern = regexp.MustCompile(`^(\d{4}_\d{2}_\d{2})(?:\.(\d+))?\.log$`)
+
+// Parses the date encoded in a log file name.
+//
+// Only names which start with the given prefix are log files, so loggers with different prefixes
+// (or without a prefix) never claim each others files.
+//
+// Parameters:
+//   - name: string - the base name of the file
+//   - prefix: string - the prefix of the log file names including the separator e.g. "access_" (may be empty)
+//   - loc: *time.Location - the location in which the date is interpreted
+//
+// Returns:
+//   - time.Time: the date of the log file (midnight)
+//   - bool: false if the name does not belong to a log file
+func parseLogFileDate(name, prefix string, loc *time.Location) (time.Time, bool) {
+	name, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	match := logFileNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return time.Time{}, false
+	}
+
+	date, err := time.ParseInLocation("2006_01_02", match[1], loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return date, true
+}
+
+// Removes the log files which are older than Options.MaxRetentionDays.
+//
+// The date is parsed from the "YYYY_MM_DD" file name (not the modification time), so the behavior is
+// deterministic. Files dated before the day which is MaxRetentionDays before the given point in time
+// are removed, e.g. with 7 days on June 10th all files up to June 2nd are removed.
+// Files which do not match the log file name pattern (including Options.FilePrefix) are never touched. Does nothing if MaxRetentionDays is 0.
+//
+// Parameters:
+//   - now: time.Time - the current point in time
+func (l *Logger) removeExpiredLogFiles(now time.Time) {
+	days := l.Options.MaxRetentionDays
+	if days <= 0 {
+		return
+	}
+
+	dir := l.Options.OutputFolderPath
+	if dir == "" {
+		dir = "."
+	}
+
+	fsys := l.fileSystem()
+	names, err := fsys.ReadDirNames(dir)
+	if err != nil {
+		l.reportError("read log folder", err)
+		return
+	}
+
+	prefix := l.filePrefix()
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -days)
+
+	for _, name := range names {
+		date, ok := parseLogFileDate(name, prefix, now.Location())
+		if !ok || !date.Before(cutoff) {
+			continue
+		}
+		if err := fsys.Remove(filepath.Join(dir, name)); err != nil {
+			l.reportError("remove expired log file", err)
+		}
+	}
+}
+
+// Describes a log file written by the logger, see LogFiles.
+type LogFileInfo struct {
+	Path       string    // Path of the file e.g. "/var/log/app/access_2023_06_01.log"
+	Date       time.Time // Date encoded in the file name (midnight)
+	Size       int64     // Size of the file in bytes
+	Compressed bool      // Set if the file is gzip compressed (".log.gz")
+}
+
+// Lists the log files of the logger in Options.OutputFolderPath, newest first.
+//
+// Only files matching the log file names of the logger are listed, i.e. "YYYY_MM_DD.log" or
+// "prefix_YYYY_MM_DD.log" (see Options.FilePrefix), the files rotated by size ("YYYY_MM_DD.N.log") and
+// gzip compressed copies of them (".log.gz"). Files of the same day are ordered by rotation number, the
+// current file of a day comes first. The size of the current log file does not include writes which are
+// still buffered (see Options.FlushInterval).
+//
+// Returns:
+//   - []LogFileInfo: the log files sorted by date, newest first
+//   - error: an error if the folder cannot be read
+//
+// Example:
+//
+//	files, err := appLogger.LogFiles()
+//	if err != nil {
+//	    return err
+//	}
+//	for _, f := range files {
+//	    fmt.Printf("%s %s %d bytes\n", f.Date.Format("2006-01-02"), f.Path, f.Size)
+//	}
+func (l *Logger) LogFiles() ([]LogFileInfo, error) {
+	dir := l.Options.OutputFolderPath
+	if dir == "" {
+		dir = "."
+	}
+
+	fsys := l.fileSystem()
+	names, err := fsys.ReadDirNames(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type logFileEntry struct {
+		LogFileInfo
+		part int // Rotation number, the current file of a day has the highest
+	}
+
+	prefix := l.filePrefix()
+	loc := l.normalizeTimestamp(generateTimestamp()).Location()
+
+	var entries []logFileEntry
+	for _, name := range names {
+		base, compressed := strings.CutSuffix(name, ".gz")
+		date, ok := parseLogFileDate(base, prefix, loc)
+		if !ok {
+			continue
+		}
+
+		path := filepath.Join(l.Options.OutputFolderPath, name)
+		info, err := fsys.Stat(path)
+		if err != nil {
+			// The file was removed in the meantime e.g. by the retention
+			continue
+		}
+
+		entries = append(entries, logFileEntry{
+			LogFileInfo: LogFileInfo{Path: path, Date: date, Size: info.Size(), Compressed: compressed},
+			part:        logFilePart(strings.TrimPrefix(base, prefix)),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.After(entries[j].Date)
+		}
+		return entries[i].part > entries[j].part
+	})
+
+	files := make([]LogFileInfo, len(entries))
+	for i, entry := range entries {
+		files[i] = entry.LogFileInfo
+	}
+	return files, nil
+}
+
+// Returns the rotation number of a log file name without prefix.
+//
+// Parameters:
+//   - name: string - the base name of the log file e.g. "2023_06_01.2.log"
+//
+// Returns:
+//   - int: the rotation number e.g. 2, or math.MaxInt for the current file of a day e.g. "2023_06_01.log"
+func logFilePart(name string) int {
+	match := logFileNamePattern.FindStringSubmatch(name)
+	if match == nil || match[2] == "" {
+		return math.MaxInt
+	}
+	part, _ := strconv.Atoi(match[2])
+	return part
+}
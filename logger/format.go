@@ -1,5 +1,11 @@
 package logger
 
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
 // The format defines how much information is being logged and in which order. Has to be defined while initalizing the logger
 // Possible key fields for the format are:
 /*
@@ -14,6 +20,17 @@ package logger
 	TIMESTAMP
 	HTTP_REQUEST
 	PROCESSED_DATA
+	GOROUTINES
+	MEMSTATS
+	FIELDS
+	CALLER
+	HTTP_BODY
+	HOSTNAME
+	PID
+	HTTP_DETAILED
+	HTTP_STATUS
+	RESPONSE_BYTES
+	SEQ
 */
 type LogFormat int
 
@@ -29,4 +46,297 @@ const (
 	FORMAT_TIMESTAMP
 	FORMAT_HTTP_REQUEST
 	FORMAT_PROCESSED_DATA
+	FORMAT_GOROUTINES     // Number of active goroutines when the entry is formatted, for diagnosing goroutine leaks
+	FORMAT_MEMSTATS       // Compact memory statistics, cached for Options.MemStatsInterval
+	FORMAT_FIELDS         // Container.Fields as sorted key=value pairs (nested object in JSON)
+	FORMAT_CALLER         // Source location of the Entry call e.g. "main.go:42", see Options.CallerSkip
+	FORMAT_HTTP_BODY      // Body of the HTTP request, capped at Options.HttpBodyMaxBytes
+	FORMAT_HOSTNAME       // Name of the host, read once when the logger is created
+	FORMAT_PID            // Id of the logging process
+	FORMAT_HTTP_DETAILED  // HTTP request with protocol, user agent, content length and Options.HttpHeaders (FORMAT_HTTP_REQUEST stays compact)
+	FORMAT_HTTP_STATUS    // Status code of the HTTP response e.g. "200"
+	FORMAT_RESPONSE_BYTES // Size of the HTTP response body e.g. "512 bytes"
+	FORMAT_SEQ            // Sequence number of the written entry e.g. "0000000042", reveals the write order and dropped lines
+
+	formatItemCount // Number of defined format items, keep last
+)
+
+// Canonical names of the format items, as listed above
+var logFormatToString = map[LogFormat]string{
+	FORMAT_STATUS:          "STATUS",
+	FORMAT_PRE_TEXT:        "PRE_TEXT",
+	FORMAT_ID:              "ID",
+	FORMAT_SOURCE:          "SOURCE",
+	FORMAT_INFO:            "INFO",
+	FORMAT_DATA:            "DATA",
+	FORMAT_ERROR:           "ERROR",
+	FORMAT_PROCESSING_TIME: "PROCESSING_TIME",
+	FORMAT_TIMESTAMP:       "TIMESTAMP",
+	FORMAT_HTTP_REQUEST:    "HTTP_REQUEST",
+	FORMAT_PROCESSED_DATA:  "PROCESSED_DATA",
+	FORMAT_GOROUTINES:      "GOROUTINES",
+	FORMAT_MEMSTATS:        "MEMSTATS",
+	FORMAT_FIELDS:          "FIELDS",
+	FORMAT_CALLER:          "CALLER",
+	FORMAT_HTTP_BODY:       "HTTP_BODY",
+	FORMAT_HOSTNAME:        "HOSTNAME",
+	FORMAT_PID:             "PID",
+	FORMAT_HTTP_DETAILED:   "HTTP_DETAILED",
+	FORMAT_HTTP_STATUS:     "HTTP_STATUS",
+	FORMAT_RESPONSE_BYTES:  "RESPONSE_BYTES",
+	FORMAT_SEQ:             "SEQ",
+}
+
+// First format item returned by RegisterFormat, leaves room for further built-in items
+const customFormatBase LogFormat = 1000
+
+// A format item registered with RegisterFormat
+type customFormat struct {
+	name   string                 // Canonical name e.g. "GIT_SHA"
+	render func(Container) string // Renders the field of an entry, an empty string skips the field
+}
+
+var (
+	customFormatsMu  sync.RWMutex                       // Guards customFormats
+	customFormats    = make(map[LogFormat]customFormat) // Registered format items by their token
+	nextCustomFormat = customFormatBase                 // Token of the next registered format item
 )
+
+// Registers a custom format item, e.g. a column with the git sha of the build.
+//
+// The returned token can be used in the format of any logger like the built-in FORMAT_* items. The render
+// function is called in the logging goroutine for every entry and must be safe for concurrent use if
+// several loggers use the item. An empty result skips the field. In the JSON output the field is written
+// as string with the lowercase name as key. The name is matched case-insensitively by ParseLogFormat.
+// Register custom items during initialization (e.g. in an init function), before they are used.
+// RegisterFormat panics if the name is empty, already taken or fn is nil.
+//
+// Parameters:
+//   - name: string - the name of the format item e.g. "GIT_SHA"
+//   - fn: func(Container) string - renders the field of an entry
+//
+// Returns:
+//   - LogFormat: the token of the new format item
+//
+// Example:
+//
+//	var formatGitSha = logger.RegisterFormat("GIT_SHA", func(logger.Container) string { return gitSha })
+//	appLogger, err := logger.NewLogger([]logger.LogFormat{logger.FORMAT_STATUS, formatGitSha, logger.FORMAT_INFO}, opt, first)
+func RegisterFormat(name string, fn func(Container) string) LogFormat {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" || fn == nil {
+		panic("logger: RegisterFormat requires a name and a render function")
+	}
+
+	// The lookup and the insert happen under one lock, so concurrent registrations of a name cannot both succeed
+	customFormatsMu.Lock()
+	defer customFormatsMu.Unlock()
+
+	if _, ok := formatByName(name); ok {
+		panic("logger: RegisterFormat called twice for format " + name)
+	}
+
+	formatItem := nextCustomFormat
+	nextCustomFormat++
+	customFormats[formatItem] = customFormat{name: name, render: fn}
+
+	return formatItem
+}
+
+// Returns a format item registered with RegisterFormat.
+//
+// Parameters:
+//   - formatItem: LogFormat - the token of the format item
+//
+// Returns:
+//   - customFormat: the registered format item
+//   - bool: false if the token was not returned by RegisterFormat
+func lookupCustomFormat(formatItem LogFormat) (customFormat, bool) {
+	customFormatsMu.RLock()
+	defer customFormatsMu.RUnlock()
+
+	custom, ok := customFormats[formatItem]
+	return custom, ok
+}
+
+// Returns the name of the format item e.g. "PRE_TEXT", implementing fmt.Stringer.
+//
+// Returns:
+//   - string: the name of the format item, or "LogFormat(<n>)" for an undefined item
+func (f LogFormat) String() string {
+	if str, ok := logFormatToString[f]; ok {
+		return str
+	}
+	if custom, ok := lookupCustomFormat(f); ok {
+		return custom.name
+	}
+	return fmt.Sprintf("LogFormat(%d)", int(f))
+}
+
+// Parses the name of a format item, e.g. read from a configuration file.
+//
+// The name is matched case-insensitively and surrounding whitespace is ignored. Items registered
+// with RegisterFormat are found as well.
+//
+// Parameters:
+//   - name: string - the name of the format item e.g. "TIMESTAMP"
+//
+// Returns:
+//   - LogFormat: the parsed format item
+//   - error: ErrInvalidFormat if the name does not belong to a format item
+func ParseLogFormat(name string) (LogFormat, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	customFormatsMu.RLock()
+	defer customFormatsMu.RUnlock()
+	if formatItem, ok := formatByName(name); ok {
+		return formatItem, nil
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrInvalidFormat, name)
+}
+
+// Looks up a built-in or registered format item by its canonical name. The caller must hold customFormatsMu.
+//
+// Parameters:
+//   - name: string - the upper case name of the format item
+//
+// Returns:
+//   - LogFormat: the format item
+//   - bool: false if no format item has the name
+func formatByName(name string) (LogFormat, bool) {
+	for formatItem, str := range logFormatToString {
+		if str == name {
+			return formatItem, true
+		}
+	}
+	for formatItem, custom := range customFormats {
+		if custom.name == name {
+			return formatItem, true
+		}
+	}
+	return 0, false
+}
+
+// Parses a list of format item names, keeping their order.
+//
+// Parameters:
+//   - names: []string - the names of the format items
+//
+// Returns:
+//   - []LogFormat: the parsed format
+//   - error: ErrInvalidFormat if a name does not belong to a format item
+//
+// Example:
+//
+//	// config.yaml: format: ["TIMESTAMP", "STATUS", "INFO"]
+//	format, err := logger.ParseLogFormats(config.Format)
+func ParseLogFormats(names []string) ([]LogFormat, error) {
+	format := make([]LogFormat, 0, len(names))
+	for _, name := range names {
+		formatItem, err := ParseLogFormat(name)
+		if err != nil {
+			return nil, err
+		}
+		format = append(format, formatItem)
+	}
+	return format, nil
+}
+
+// Returns the active format of the logger.
+//
+// Returns:
+//   - []LogFormat: the format items, must not be modified
+func (l *Logger) format() []LogFormat {
+	l.formatMu.RLock()
+	defer l.formatMu.RUnlock()
+	return l.Format
+}
+
+// Replaces the format of the logger while it is running, e.g. to add FORMAT_PROCESSED_DATA during an incident.
+//
+// The format is validated like in NewLogger. Entries which are already queued are written with the new format.
+// The slice is copied, so the caller may reuse it. Use SetFormat instead of assigning Logger.Format, which is
+// not safe while the logger is running.
+//
+// Parameters:
+//   - format: []LogFormat - the new format (order will be considered in logs)
+//
+// Returns:
+//   - error: ErrInvalidFormat if the format contains an undefined item, the format is unchanged then
+//
+// Example:
+//
+//	// Admin endpoint toggling verbose output
+//	err := appLogger.SetFormat([]logger.LogFormat{logger.FORMAT_STATUS, logger.FORMAT_INFO, logger.FORMAT_PROCESSED_DATA})
+func (l *Logger) SetFormat(format []LogFormat) error {
+	if err := validateFormat(format); err != nil {
+		return err
+	}
+	l.warnDuplicateFormatItems(format)
+
+	format = append([]LogFormat(nil), format...)
+
+	l.formatMu.Lock()
+	defer l.formatMu.Unlock()
+	l.Format = format
+
+	return nil
+}
+
+// Reports whether the format of the logger or Options.FileFormat contains the given item.
+//
+// Parameters:
+//   - formatItem: LogFormat - the item to look for
+//
+// Returns:
+//   - bool: true if the item is configured
+func (l *Logger) hasFormat(formatItem LogFormat) bool {
+	for _, item := range l.format() {
+		if item == formatItem {
+			return true
+		}
+	}
+	for _, item := range l.Options.FileFormat {
+		if item == formatItem {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks that the format only contains defined format items (built-in or registered with RegisterFormat).
+//
+// Repeated items are allowed (they are rendered repeatedly), see warnDuplicateFormatItems.
+//
+// Parameters:
+//   - format: []LogFormat - the configured format
+//
+// Returns:
+//   - error: ErrInvalidFormat if an item is outside the defined FORMAT_* range and not registered, nil otherwise
+func validateFormat(format []LogFormat) error {
+	for i, formatItem := range format {
+		if _, custom := lookupCustomFormat(formatItem); !custom && (formatItem < 0 || formatItem >= formatItemCount) {
+			return fmt.Errorf("%w at index %d: %d", ErrInvalidFormat, i, formatItem)
+		}
+	}
+
+	return nil
+}
+
+// Warns about format items which are configured more than once.
+//
+// Repeated items are rendered repeatedly, which is most likely a mistake. A warning wrapping ErrDuplicateFormat
+// is reported for every repeat, see reportError (printed to STDOUT if no Options.ErrorHandler is set).
+//
+// Parameters:
+//   - format: []LogFormat - the configured format
+func (l *Logger) warnDuplicateFormatItems(format []LogFormat) {
+	seen := make(map[LogFormat]bool, len(format))
+	for i, formatItem := range format {
+		if seen[formatItem] {
+			l.reportError("validate format", fmt.Errorf("%w: %s (index %d)", ErrDuplicateFormat, formatItem, i))
+		}
+		seen[formatItem] = true
+	}
+}
@@ -0,0 +1,228 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLogFormatNames(t *testing.T) {
+	// Every defined format item has a name which parses back to it
+	for formatItem := LogFormat(0); formatItem < formatItemCount; formatItem++ {
+		name := formatItem.String()
+		if _, ok := logFormatToString[formatItem]; !ok {
+			t.Errorf("Unexpected result: format item %d has no name", formatItem)
+		}
+
+		parsed, err := ParseLogFormat(name)
+		if err != nil {
+			t.Fatalf("Unexpected result: " + err.Error())
+		}
+		if parsed != formatItem {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", formatItem, parsed)
+		}
+	}
+}
+
+func TestParseLogFormats(t *testing.T) {
+	format, err := ParseLogFormats([]string{"TIMESTAMP", "status", " info "})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	expected := []LogFormat{FORMAT_TIMESTAMP, FORMAT_STATUS, FORMAT_INFO}
+	if !reflect.DeepEqual(format, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, format)
+	}
+
+	if _, err := ParseLogFormats([]string{"STATUS", "COLOR"}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrInvalidFormat, err)
+	}
+}
+
+// Registers a format item which is removed again at the end of the test, so the test can run repeatedly (-count)
+func registerTestFormat(t *testing.T, name string, fn func(Container) string) LogFormat {
+	formatItem := RegisterFormat(name, fn)
+	t.Cleanup(func() {
+		customFormatsMu.Lock()
+		defer customFormatsMu.Unlock()
+		delete(customFormats, formatItem)
+	})
+	return formatItem
+}
+
+func TestRegisterFormat(t *testing.T) {
+	formatGitSha := registerTestFormat(t, "git_sha", func(c Container) string {
+		if c.Status == STATUS_TRACE {
+			return ""
+		}
+		return "3f2a9c1"
+	})
+
+	if expected, actual := "GIT_SHA", formatGitSha.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+	if formatItem, err := ParseLogFormat("git_sha"); err != nil || formatItem != formatGitSha {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v %v", formatGitSha, formatItem, err)
+	}
+
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, formatGitSha, FORMAT_INFO}, Options{}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	// Close first, so the first entry is written before the options are changed below
+	l.Close()
+
+	expected := "INFO 3f2a9c1 user created"
+	if actual := l.FormatEntry(Container{Status: STATUS_INFO, Info: "user created"}); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+	expected = "TRACE user created"
+	if actual := l.FormatEntry(Container{Status: STATUS_TRACE, Info: "user created"}); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	l.Options.OutputFormat = OUTPUT_JSON
+	expected = `{"status":"INFO","git_sha":"3f2a9c1","info":"user created"}`
+	if actual := l.FormatEntry(Container{Status: STATUS_INFO, Info: "user created"}); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Names are unique, also across the built-in items
+	for _, name := range []string{"GIT_SHA", "info"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Unexpected result: registering %s did not panic", name)
+				}
+			}()
+			RegisterFormat(name, func(Container) string { return "" })
+		}()
+	}
+}
+
+func TestRegisterFormatConcurrent(t *testing.T) {
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		registered []LogFormat
+		start      = make(chan struct{})
+	)
+
+	// Only one of the concurrent registrations of the same name may succeed, the others panic
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { recover() }()
+			<-start
+			formatItem := registerTestFormat(t, "REGION", func(Container) string { return "eu" })
+			mu.Lock()
+			registered = append(registered, formatItem)
+			mu.Unlock()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if len(registered) != 1 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 1, len(registered))
+	}
+}
+
+func TestHostnameAndPid(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("hostname unavailable: " + err.Error())
+	}
+
+	l, err := NewLogger([]LogFormat{FORMAT_HOSTNAME, FORMAT_PID, FORMAT_INFO}, Options{}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	// Close first, so the first entry is written before the options are changed below
+	l.Close()
+
+	expected := fmt.Sprintf("%s %d started", hostname, os.Getpid())
+	if actual := l.FormatEntry(Container{Info: "started"}); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	l.Options.OutputFormat = OUTPUT_JSON
+	expected = fmt.Sprintf(`{"hostname":%q,"pid":%d,"info":"started"}`, hostname, os.Getpid())
+	if actual := l.FormatEntry(Container{Info: "started"}); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestSetFormat(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{OutputToStdout: true, Stdout: &capturedOutput}, Container{Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	// Swap the format while entries are being written
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			l.Info("entry")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := l.SetFormat([]LogFormat{FORMAT_STATUS, FORMAT_INFO}); err != nil {
+			t.Fatalf("Unexpected result: " + err.Error())
+		}
+	}
+	<-done
+
+	format := []LogFormat{FORMAT_INFO, FORMAT_STATUS}
+	if err := l.SetFormat(format); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	format[0] = FORMAT_ID // The logger keeps its own copy
+
+	l.Warn("last")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(capturedOutput.String()), "\n")
+	if expected, actual := "last WARN", lines[len(lines)-1]; expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// An invalid format is rejected and the format is unchanged
+	if err := l.SetFormat([]LogFormat{LogFormat(99)}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrInvalidFormat, err)
+	}
+	if expected, actual := []LogFormat{FORMAT_INFO, FORMAT_STATUS}, l.format(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestFormatSeq(t *testing.T) {
+	l, buf := NewTestLogger([]LogFormat{FORMAT_SEQ, FORMAT_INFO})
+	defer l.Close()
+	l.SetMinStatus(STATUS_INFO)
+
+	// Skipped entries are not written, so they don't take a number
+	l.Info("first")
+	l.Trace("skipped")
+	l.Info("second")
+
+	expected := "0000000001 first\n0000000002 second\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Entries which are only formatted have no number
+	if expected, actual := "third", l.FormatEntry(Container{Info: "third"}); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
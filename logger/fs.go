@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"io"
+	"os"
+)
+
+// Abstracts the file operations of the logger, so that file handling can be tested without touching the disk
+type fileSystem interface {
+	OpenFile(name string, flag int, perm os.FileMode) (logFile, error)
+	CreateTemp(dir, pattern string) (logFile, string, error)
+	Remove(name string) error
+	Rename(oldName, newName string) error
+	Stat(name string) (os.FileInfo, error)
+	MkdirAll(path string, perm os.FileMode) error
+	ReadDirNames(dir string) ([]string, error)
+	Symlink(target, name string) error
+	Link(target, name string) error
+}
+
+// A file opened by a fileSystem
+type logFile interface {
+	io.Writer
+	io.Closer
+}
+
+// Implements fileSystem with the functions of the os package
+type osFileSystem struct{}
+
+func (osFileSystem) OpenFile(name string, flag int, perm os.FileMode) (logFile, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (osFileSystem) CreateTemp(dir, pattern string) (logFile, string, error) {
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, file.Name(), nil
+}
+
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFileSystem) Rename(oldName, newName string) error {
+	return os.Rename(oldName, newName)
+}
+
+func (osFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (osFileSystem) ReadDirNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (osFileSystem) Symlink(target, name string) error {
+	return os.Symlink(target, name)
+}
+
+func (osFileSystem) Link(target, name string) error {
+	return os.Link(target, name)
+}
+
+// Returns the file system used by the logger.
+//
+// Returns:
+//   - fileSystem: the configured file system, or the real file system if none is set
+func (l *Logger) fileSystem() fileSystem {
+	if l.fs == nil {
+		return osFileSystem{}
+	}
+	return l.fs
+}
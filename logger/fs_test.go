@@ -0,0 +1,635 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// In-memory fileSystem for tests
+type memFileSystem struct {
+	mu     sync.Mutex
+	files  map[string]*bytes.Buffer
+	opens  int // Number of successful OpenFile calls
+	writes int // Number of Write calls, each is a syscall on a real file
+}
+
+func newMemFileSystem() *memFileSystem {
+	return &memFileSystem{files: make(map[string]*bytes.Buffer)}
+}
+
+func (m *memFileSystem) OpenFile(name string, flag int, perm os.FileMode) (logFile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.files[name]
+	switch {
+	case ok && flag&os.O_EXCL != 0 && flag&os.O_CREATE != 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	case !ok:
+		buf = &bytes.Buffer{}
+		m.files[name] = buf
+	case flag&os.O_TRUNC != 0:
+		buf.Reset()
+	}
+
+	m.opens++
+	return &memFile{fs: m, buf: buf}, nil
+}
+
+func (m *memFileSystem) CreateTemp(dir, pattern string) (logFile, string, error) {
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, strings.Replace(pattern, "*", fmt.Sprint(i), 1))
+		file, err := m.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if !errors.Is(err, os.ErrExist) {
+			return file, name, err
+		}
+	}
+}
+
+func (m *memFileSystem) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.files[name]; !ok {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+func (m *memFileSystem) Rename(oldName, newName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.files[oldName]
+	if !ok {
+		return &os.PathError{Op: "rename", Path: oldName, Err: os.ErrNotExist}
+	}
+	delete(m.files, oldName)
+	m.files[newName] = buf
+	return nil
+}
+
+func (m *memFileSystem) Stat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.files[name]
+	if !ok {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return memFileInfo{name: name, size: int64(buf.Len())}, nil
+}
+
+func (m *memFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	// Folders are implicit, every path can hold files
+	return nil
+}
+
+func (m *memFileSystem) ReadDirNames(dir string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	if dir == "." {
+		prefix = ""
+	}
+
+	var names []string
+	for name := range m.files {
+		if rest, ok := strings.CutPrefix(name, prefix); ok && !strings.Contains(rest, "/") {
+			names = append(names, rest)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Links are created as files sharing the buffer of their target
+func (m *memFileSystem) Symlink(target, name string) error {
+	return m.Link(filepath.Join(filepath.Dir(name), target), name)
+}
+
+func (m *memFileSystem) Link(target, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.files[target]
+	if !ok {
+		return &os.LinkError{Op: "link", Old: target, New: name, Err: os.ErrNotExist}
+	}
+	if _, ok := m.files[name]; ok {
+		return &os.LinkError{Op: "link", Old: target, New: name, Err: os.ErrExist}
+	}
+	m.files[name] = buf
+	return nil
+}
+
+// Returns the content of a file, or an empty string if it does not exist
+func (m *memFileSystem) content(name string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if buf, ok := m.files[name]; ok {
+		return buf.String()
+	}
+	return ""
+}
+
+type memFile struct {
+	fs  *memFileSystem
+	buf *bytes.Buffer
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	f.fs.writes++
+	return f.buf.Write(p)
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
+
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() os.FileMode  { return 0644 }
+func (i memFileInfo) ModTime() time.Time { return time.Time{} }
+func (i memFileInfo) IsDir() bool        { return false }
+func (i memFileInfo) Sys() any           { return nil }
+
+func TestWriteLogToFileCachesHandlePerDay(t *testing.T) {
+	fsys := newMemFileSystem()
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: "logs/"}, fs: fsys}
+
+	day1 := time.Date(2023, 6, 1, 23, 59, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Minute)
+
+	l.writeLogToFile("a", &Container{Timestamp: day1})
+	l.writeLogToFile("b", &Container{Timestamp: day1})
+	l.writeLogToFile("c", &Container{Timestamp: day2})
+
+	if fsys.opens != 2 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 2, fsys.opens)
+	}
+
+	expected := "a\nb\n"
+	if actual := fsys.content("logs/2023_06_01.log"); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+	expected = "c\n"
+	if actual := fsys.content("logs/2023_06_02.log"); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestCheckWritePermissionRemovesTestFile(t *testing.T) {
+	fsys := newMemFileSystem()
+
+	ok, err := checkWritePermission(fsys, "logs/")
+	if !ok || err != nil {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v %v", true, ok, err)
+	}
+	if len(fsys.files) != 0 {
+		t.Errorf("Unexpected result: test file was not removed")
+	}
+
+	// A left over test file of another logger does not make the check fail
+	fsys.files["logs/testfile-0.tmp"] = &bytes.Buffer{}
+	ok, err = checkWritePermission(fsys, "logs/")
+	if !ok || err != nil {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v %v", true, ok, err)
+	}
+	if len(fsys.files) != 1 {
+		t.Errorf("Unexpected result: test file was not removed")
+	}
+}
+
+func TestMaxFileSizeBytesRotates(t *testing.T) {
+	fsys := newMemFileSystem()
+	fsys.files["logs/2023_06_01.1.log"] = bytes.NewBufferString("older\n")
+
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: "logs/", MaxFileSizeBytes: 10}, fs: fsys}
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, message := range []string{"aaaa", "bbbb", "cccc", "dddddddddddddd"} {
+		l.writeLogToFile(message, &Container{Timestamp: ts})
+	}
+
+	expected := map[string]string{
+		"logs/2023_06_01.1.log": "older\n",
+		"logs/2023_06_01.2.log": "aaaa\nbbbb\n",
+		"logs/2023_06_01.3.log": "cccc\n",
+		"logs/2023_06_01.log":   "dddddddddddddd\n",
+	}
+	for name, content := range expected {
+		if actual := fsys.content(name); actual != content {
+			t.Errorf("Unexpected result for %s.\nExpected:\n%#v\nGot:\n%#v", name, content, actual)
+		}
+	}
+	if len(fsys.files) != len(expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", len(expected), len(fsys.files))
+	}
+}
+
+func TestLineEndingCRLF(t *testing.T) {
+	fsys := newMemFileSystem()
+
+	// The CRLF line ending counts towards the maximum file size
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: "logs/", MaxFileSizeBytes: 12, LineEnding: "\r\n"}, fs: fsys}
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, message := range []string{"aaaa", "bbbb", "cccc"} {
+		l.writeLogToFile(message, &Container{Timestamp: ts})
+	}
+
+	expected := map[string]string{
+		"logs/2023_06_01.1.log": "aaaa\r\nbbbb\r\n",
+		"logs/2023_06_01.log":   "cccc\r\n",
+	}
+	for name, content := range expected {
+		if actual := fsys.content(name); actual != content {
+			t.Errorf("Unexpected result for %s.\nExpected:\n%#v\nGot:\n%#v", name, content, actual)
+		}
+	}
+
+	var out bytes.Buffer
+	l = &Logger{Format: []LogFormat{FORMAT_INFO}, Options: Options{LineEnding: "\r\n", Outputs: []io.Writer{&out}}}
+	l.writeEntry(Container{Timestamp: ts, Info: "dddd"})
+	// Line breaks inside the entry get the same line ending, existing CRLF is kept
+	l.writeEntry(Container{Timestamp: ts, Info: "eeee\nffff\r\ngggg"})
+
+	if expected, actual := "dddd\r\neeee\r\nffff\r\ngggg\r\n", out.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestLogFileNameUsesPathSeparator(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// The folder is given with the native separator (backslashes on Windows), with and without a trailing one
+	for _, folder := range []string{filepath.Join("var", "log", "app"), filepath.Join("var", "log", "app") + string(filepath.Separator)} {
+		l := &Logger{Options: Options{OutputFolderPath: folder, FilePrefix: "access"}}
+
+		expected := filepath.Join("var", "log", "app", "access_2023_06_01.log")
+		if actual := l.logFileName(ts); actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+
+		date, ok := parseLogFileDate(filepath.Base(expected), l.filePrefix(), time.UTC)
+		if !ok || !date.Equal(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v %v", "2023-06-01", date, ok)
+		}
+	}
+}
+
+func TestLogFiles(t *testing.T) {
+	fsys := newMemFileSystem()
+	for name, content := range map[string]string{
+		"logs/access_2023_06_01.log":    "a\n",
+		"logs/access_2023_06_01.1.log":  "bb\n",
+		"logs/access_2023_06_01.2.log":  "ccc\n",
+		"logs/access_2023_05_31.log.gz": "dddd",
+		"logs/access_2023_06_02.log":    "",
+		"logs/2023_06_03.log":           "other logger\n",
+		"logs/access_current.log":       "a\n",
+		"logs/notes.txt":                "",
+	} {
+		fsys.files[name] = bytes.NewBufferString(content)
+	}
+
+	l := &Logger{Options: Options{OutputFolderPath: "logs/", FilePrefix: "access", UseUTC: true}, fs: fsys}
+
+	files, err := l.LogFiles()
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	day := func(d int) time.Time { return time.Date(2023, 6, d, 0, 0, 0, 0, time.UTC) }
+	expected := []LogFileInfo{
+		{Path: filepath.Join("logs", "access_2023_06_02.log"), Date: day(2), Size: 0},
+		{Path: filepath.Join("logs", "access_2023_06_01.log"), Date: day(1), Size: 2},
+		{Path: filepath.Join("logs", "access_2023_06_01.2.log"), Date: day(1), Size: 4},
+		{Path: filepath.Join("logs", "access_2023_06_01.1.log"), Date: day(1), Size: 3},
+		{Path: filepath.Join("logs", "access_2023_05_31.log.gz"), Date: time.Date(2023, 5, 31, 0, 0, 0, 0, time.UTC), Size: 4, Compressed: true},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, files)
+	}
+}
+
+func TestRemoveExpiredLogFiles(t *testing.T) {
+	fsys := newMemFileSystem()
+	for _, name := range []string{
+		"logs/2023_06_01.log",
+		"logs/2023_06_02.log",
+		"logs/2023_06_02.1.log",
+		"logs/2023_06_03.log",
+		"logs/2023_06_09.log",
+		"logs/notes.txt",
+		"logs/2023_06_01.log.bak",
+	} {
+		fsys.files[name] = &bytes.Buffer{}
+	}
+
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: "logs/", MaxRetentionDays: 7}, fs: fsys}
+
+	// Writing the first entry of a new day triggers the cleanup
+	l.writeLogToFile("a", &Container{Timestamp: time.Date(2023, 6, 9, 23, 0, 0, 0, time.UTC)})
+	l.writeLogToFile("b", &Container{Timestamp: time.Date(2023, 6, 10, 1, 0, 0, 0, time.UTC)})
+
+	expected := []string{"2023_06_01.log.bak", "2023_06_03.log", "2023_06_09.log", "2023_06_10.log", "notes.txt"}
+	actual, _ := fsys.ReadDirNames("logs")
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestFilePrefix(t *testing.T) {
+	fsys := newMemFileSystem()
+	for _, name := range []string{
+		"logs/2023_06_01.log",
+		"logs/app_2023_06_01.log",
+		"logs/access_2023_06_01.log",
+		"logs/access_2023_06_01.1.log",
+		"logs/access_2023_06_09.log",
+	} {
+		fsys.files[name] = &bytes.Buffer{}
+	}
+
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: "logs/", FilePrefix: "access", MaxRetentionDays: 7}, fs: fsys}
+
+	l.writeLogToFile("a", &Container{Timestamp: time.Date(2023, 6, 9, 23, 0, 0, 0, time.UTC)})
+	l.writeLogToFile("b", &Container{Timestamp: time.Date(2023, 6, 10, 1, 0, 0, 0, time.UTC)})
+
+	// Only the expired files with the prefix of this logger are removed
+	expected := []string{"2023_06_01.log", "access_2023_06_09.log", "access_2023_06_10.log", "app_2023_06_01.log"}
+	actual, _ := fsys.ReadDirNames("logs")
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	if expected, actual := "b\n", fsys.content("logs/access_2023_06_10.log"); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestUseUTCFileName(t *testing.T) {
+	// 23:30 in New York is already the next day in UTC
+	timestamp := time.Date(2023, 6, 1, 23, 30, 0, 0, time.FixedZone("EDT", -4*60*60))
+
+	cases := map[bool]string{
+		false: "logs/2023_06_01.log",
+		true:  "logs/2023_06_02.log",
+	}
+
+	for useUTC, expected := range cases {
+		fsys := newMemFileSystem()
+		l := &Logger{
+			Format:  []LogFormat{FORMAT_TIMESTAMP},
+			Options: Options{OutputToFile: true, OutputFolderPath: "logs/", UseUTC: useUTC},
+			fs:      fsys,
+		}
+
+		l.writeEntry(Container{Timestamp: timestamp})
+
+		if _, ok := fsys.files[expected]; !ok {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, fsys.files)
+		}
+		if useUTC && fsys.content(expected) != "2023-06-02T03:30:00Z\n" {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "2023-06-02T03:30:00Z\n", fsys.content(expected))
+		}
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	var reported []error
+
+	l := &Logger{
+		Options: Options{
+			OutputToFile:     true,
+			OutputFolderPath: "missing/",
+			ErrorHandler:     func(err error) { reported = append(reported, err) },
+		},
+		fs: &failingFileSystem{newMemFileSystem()},
+	}
+
+	l.writeLogToFile("a", &Container{Timestamp: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)})
+
+	if len(reported) != 1 || !errors.Is(reported[0], os.ErrNotExist) {
+		t.Fatalf("Unexpected result: %v", reported)
+	}
+
+	expected := "failed to open log file: " + os.ErrNotExist.Error()
+	if actual := reported[0].Error(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+// A file system on which files cannot be opened
+type failingFileSystem struct {
+	*memFileSystem
+}
+
+func (f *failingFileSystem) OpenFile(name string, flag int, perm os.FileMode) (logFile, error) {
+	return nil, os.ErrNotExist
+}
+
+// Writes like the logger did before the handle was cached: open, write and close for every line
+func BenchmarkWriteLogToFilePerLineOpen(b *testing.B) {
+	name := filepath.Join(b.TempDir(), "2023_06_01.log")
+
+	for i := 0; i < b.N; i++ {
+		file, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			b.Fatalf("Unexpected result: " + err.Error())
+		}
+		fmt.Fprintln(file, "INFO 5f322ac4ba handler/user This is an information message")
+		file.Close()
+	}
+}
+
+func BenchmarkWriteLogToFileCachedHandle(b *testing.B) {
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: b.TempDir()}}
+	defer l.closeLogFile()
+
+	c := &Container{Timestamp: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)}
+	for i := 0; i < b.N; i++ {
+		l.writeLogToFile("INFO 5f322ac4ba handler/user This is an information message", c)
+	}
+}
+
+func TestFlushIntervalBuffersWrites(t *testing.T) {
+	fsys := newMemFileSystem()
+	l := &Logger{
+		Options: Options{OutputToFile: true, OutputFolderPath: "logs/", FlushInterval: time.Hour},
+		fs:      fsys,
+	}
+
+	c := &Container{Timestamp: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l.writeLogToFile("first", c)
+	l.writeLogToFile("second", c)
+
+	// Nothing is written before the buffer is flushed
+	if actual := fsys.content("logs/2023_06_01.log"); actual != "" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "", actual)
+	}
+
+	l.flushLogFile()
+
+	expected := "first\nsecond\n"
+	if actual := fsys.content("logs/2023_06_01.log"); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Closing the file writes the remaining buffer
+	l.writeLogToFile("third", c)
+	l.closeLogFile()
+
+	expected += "third\n"
+	if actual := fsys.content("logs/2023_06_01.log"); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func BenchmarkWriteLogToFileBuffered(b *testing.B) {
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: b.TempDir(), FlushInterval: time.Second}}
+	defer l.closeLogFile()
+
+	c := &Container{Timestamp: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)}
+	for i := 0; i < b.N; i++ {
+		l.writeLogToFile("INFO 5f322ac4ba handler/user This is an information message", c)
+	}
+}
+
+// Creates a running logger which writes its log files to the given file system
+func newMemLogger(fsys *memFileSystem, opt Options) *Logger {
+	opt.OutputToFile = true
+	l := &Logger{
+		Format:         []LogFormat{FORMAT_STATUS, FORMAT_INFO},
+		LogChan:        make(chan Container, opt.ChannelBufferSize),
+		StatusCounters: make(map[LogStatus]int),
+		Options:        opt,
+		done:           make(chan struct{}),
+		fs:             fsys,
+	}
+	go l.processLogs()
+	return l
+}
+
+// Returns the number of writes to the files of the file system
+func (m *memFileSystem) writeCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.writes
+}
+
+func TestProcessLogsBatchesFileWrites(t *testing.T) {
+	fsys := newMemFileSystem()
+	l := newMemLogger(fsys, Options{OutputFolderPath: "logs/", ChannelBufferSize: 10})
+	defer l.Close()
+
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	l.Entry(Container{Status: STATUS_INFO, Info: "started", Timestamp: ts})
+	l.Flush()
+	before := fsys.writeCount()
+
+	// Hold the processing back until all entries are queued, so they are drained as one batch
+	l.processMu.Lock()
+	for i := 0; i < 10; i++ {
+		l.Entry(Container{Status: STATUS_INFO, Info: fmt.Sprint(i), Timestamp: ts})
+	}
+	l.processMu.Unlock()
+	l.Flush()
+
+	if actual := fsys.writeCount() - before; actual != 1 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 1, actual)
+	}
+
+	expected := "INFO started\nINFO 0\nINFO 1\nINFO 2\nINFO 3\nINFO 4\nINFO 5\nINFO 6\nINFO 7\nINFO 8\nINFO 9\n"
+	if actual := fsys.content("logs/2023_06_01.log"); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+// Writes every entry with its own write, like processLogs did before batching
+func BenchmarkProcessLogsWritePerEntry(b *testing.B) {
+	fsys := newMemFileSystem()
+	l := newMemLogger(fsys, Options{OutputFolderPath: "logs/", Synchronous: true})
+	defer l.Close()
+
+	benchmarkConcurrentEntries(b, l, fsys)
+}
+
+func BenchmarkProcessLogsBatchedWrites(b *testing.B) {
+	fsys := newMemFileSystem()
+	l := newMemLogger(fsys, Options{OutputFolderPath: "logs/", ChannelBufferSize: 1024})
+	defer l.Close()
+
+	benchmarkConcurrentEntries(b, l, fsys)
+}
+
+// Logs from concurrent goroutines and reports the number of file writes per entry
+func benchmarkConcurrentEntries(b *testing.B, l *Logger, fsys *memFileSystem) {
+	c := Container{Status: STATUS_INFO, Info: "This is an information message", Timestamp: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Entry(c)
+		}
+	})
+	l.Flush()
+	b.StopTimer()
+
+	b.ReportMetric(float64(fsys.writeCount())/float64(b.N), "writes/entry")
+}
+
+func TestCurrentLogLink(t *testing.T) {
+	dir := t.TempDir()
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: dir, CurrentLogLink: true, MaxFileSizeBytes: 10}}
+	defer l.closeLogFile()
+
+	link := filepath.Join(dir, "current.log")
+
+	day1 := &Container{Timestamp: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l.writeLogToFile("first", day1)
+
+	target, err := os.Readlink(link)
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if target != "2023_06_01.log" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "2023_06_01.log", target)
+	}
+
+	// The link follows size rotations and new days
+	l.writeLogToFile("second", day1)
+	l.writeLogToFile("third", &Container{Timestamp: time.Date(2023, 6, 2, 12, 0, 0, 0, time.UTC)})
+
+	content, err := os.ReadFile(link)
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if string(content) != "third\n" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "third\n", string(content))
+	}
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+)
+
+// Wraps every writer in Options.GzipOutputs in a gzip stream.
+//
+// The gzip writers compress the lines as they are written and buffer the compressed bytes until they
+// are flushed. Like the log file, they are flushed after every batch of entries, or only every Options.FlushInterval
+// if it is set (fewer flushes compress better). Flush flushes them right away and Close also writes the gzip trailer.
+func (l *Logger) openGzipOutputs() {
+	for _, w := range l.Options.GzipOutputs {
+		l.gzipOutputs = append(l.gzipOutputs, gzip.NewWriter(w))
+	}
+}
+
+// Writes the compressed bytes buffered by the gzip outputs to the underlying writers.
+//
+// A flushed gzip stream can be decompressed up to the last written line, while the stream stays open
+// for further lines. Nothing is written if no line was written since the last flush, as every flush
+// adds a sync marker to the streams. The caller must hold processMu, as the gzip writers are not safe for concurrent use.
+func (l *Logger) flushGzipOutputs() {
+	if !l.gzipPending {
+		return
+	}
+	l.gzipPending = false
+
+	for _, zw := range l.gzipOutputs {
+		if err := zw.Flush(); err != nil {
+			l.reportError("flush gzip output", err)
+		}
+	}
+}
+
+// Terminates the gzip streams, writing the remaining compressed bytes and the gzip trailer.
+//
+// The underlying writers are not closed, they are owned by the caller.
+//
+// Returns:
+//   - error: the errors which occurred while terminating the streams
+func (l *Logger) closeGzipOutputs() error {
+	var err error
+	for _, zw := range l.gzipOutputs {
+		err = errors.Join(err, zw.Close())
+	}
+	return err
+}
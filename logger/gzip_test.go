@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+)
+
+// Decompresses the gzip stream in the buffer, a stream without trailer is read up to the last flush
+func gunzip(t *testing.T, buf *bytes.Buffer) (string, error) {
+	t.Helper()
+
+	zr, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	content, err := io.ReadAll(zr)
+	return string(content), err
+}
+
+func TestGzipOutputsFlush(t *testing.T) {
+	var compressed bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{GzipOutputs: []io.Writer{&compressed}}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	defer l.Close()
+
+	l.Entry(Container{Status: STATUS_WARN, Info: "disk almost full"})
+	l.Flush()
+
+	// The stream is still open, so it ends without trailer after the flushed lines
+	expected := "INFO started\nWARN disk almost full\n"
+	actual, err := gunzip(t, &compressed)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", io.ErrUnexpectedEOF, err)
+	}
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestGzipOutputsFlushedPerBatch(t *testing.T) {
+	var compressed bytes.Buffer
+
+	// Without FlushInterval the stream is flushed once the entry is written, Flush is not needed
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		GzipOutputs: []io.Writer{&compressed},
+		Synchronous: true,
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	defer l.Close()
+
+	l.Entry(Container{Status: STATUS_WARN, Info: "disk almost full"})
+
+	expected := "WARN disk almost full\n"
+	actual, _ := gunzip(t, &compressed)
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestGzipOutputsClose(t *testing.T) {
+	var compressed, plain bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		Outputs:     []io.Writer{&plain},
+		GzipOutputs: []io.Writer{&compressed},
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	l.Entry(Container{Status: STATUS_ERROR, Info: "upload failed"})
+	if err := l.Close(); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	// Close terminates the stream, so it decompresses completely
+	expected := "INFO started\nERROR upload failed\n"
+	actual, err := gunzip(t, &compressed)
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// The plain outputs are not affected
+	if plain.String() != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, plain.String())
+	}
+}
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Default maximum number of request body bytes recorded by FORMAT_HTTP_BODY
+const defaultHttpBodyMaxBytes = 4096
+
+// Marks a request body which was cut off at Options.HttpBodyMaxBytes
+const httpBodyTruncatedText = "... (truncated)"
+
+// A request body whose beginning was read for logging, reading continues with the recorded bytes
+// followed by the rest of the original body
+type restoredBody struct {
+	io.Reader
+	io.Closer
+}
+
+// Records the body of the HTTP request of a log entry and restores it for the downstream handlers.
+//
+// At most Options.HttpBodyMaxBytes (default 4096) bytes are read, so huge payloads are not logged.
+// The body of the request is replaced by a reader which returns the recorded bytes followed by the
+// unread rest of the original body, so the request can still be read completely afterwards.
+//
+// Parameters:
+//   - r: *http.Request - the HTTP request, may be nil
+//
+// Returns:
+//   - string: the recorded body, followed by "... (truncated)" if it exceeds the limit
+func (l *Logger) captureHttpBody(r *http.Request) string {
+	if r == nil || r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+
+	limit := l.Options.HttpBodyMaxBytes
+	if limit <= 0 {
+		limit = defaultHttpBodyMaxBytes
+	}
+
+	// Read one byte more than the limit to detect a truncated body
+	recorded, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	r.Body = restoredBody{Reader: io.MultiReader(bytes.NewReader(recorded), r.Body), Closer: r.Body}
+	if err != nil {
+		l.reportError("read HTTP request body", err)
+	}
+
+	if len(recorded) > limit {
+		return string(recorded[:limit]) + httpBodyTruncatedText
+	}
+	return string(recorded)
+}
+
+// Returns the address of the client which sent an HTTP request.
+//
+// Behind a proxy RemoteAddr is the address of the proxy. If Options.ClientIPHeader is set and the request
+// carries that header, its value is used instead: the first address for "X-Forwarded-For" (which lists
+// the client followed by the proxies), the complete value for other headers like "X-Real-IP". Clients can
+// send these headers themselves, so the option must only be set if a trusted proxy overwrites them.
+//
+// Parameters:
+//   - r: *http.Request - the HTTP request
+//
+// Returns:
+//   - string: the client address, RemoteAddr if the header is not configured or not present
+func (l *Logger) clientAddr(r *http.Request) string {
+	header := l.Options.ClientIPHeader
+	if header == "" {
+		return r.RemoteAddr
+	}
+
+	value := r.Header.Get(header)
+	if http.CanonicalHeaderKey(header) == "X-Forwarded-For" {
+		value, _, _ = strings.Cut(value, ",")
+	}
+	if value = strings.TrimSpace(value); value != "" {
+		return value
+	}
+	return r.RemoteAddr
+}
+
+// Details of an HTTP request logged by FORMAT_HTTP_DETAILED
+type httpRequestDetails struct {
+	RemoteAddr    string            `json:"remote_addr"`
+	Method        string            `json:"method"`
+	URL           string            `json:"url"`
+	Proto         string            `json:"proto,omitempty"`
+	UserAgent     string            `json:"user_agent,omitempty"`
+	ContentLength int64             `json:"content_length,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+// Collects the details of an HTTP request for access logs.
+//
+// Besides the remote address, method and URL the protocol, the user agent, the content length and the
+// headers listed in Options.HttpHeaders are collected. Headers listed in Options.RedactKeys are masked.
+// Headers which are not set are skipped, repeated headers are joined with ", ".
+//
+// Parameters:
+//   - r: *http.Request - the HTTP request, may be nil
+//
+// Returns:
+//   - *httpRequestDetails: the details, nil if the request is nil
+func (l *Logger) getHttpRequestDetails(r *http.Request) *httpRequestDetails {
+	if r == nil {
+		return nil
+	}
+
+	details := &httpRequestDetails{
+		RemoteAddr:    l.clientAddr(r),
+		Method:        r.Method,
+		URL:           r.URL.String(),
+		Proto:         r.Proto,
+		UserAgent:     r.UserAgent(),
+		ContentLength: r.ContentLength,
+	}
+	if details.ContentLength < 0 {
+		details.ContentLength = 0 // Unknown
+	}
+
+	for _, name := range l.Options.HttpHeaders {
+		values := r.Header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if details.Headers == nil {
+			details.Headers = make(map[string]string, len(l.Options.HttpHeaders))
+		}
+
+		name = http.CanonicalHeaderKey(name)
+		if l.isRedactedKey(name) {
+			details.Headers[name] = redactedValue
+		} else {
+			details.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+
+	return details
+}
+
+// Formats the details of an HTTP request as text.
+//
+// The compact request (see Logger.getHttpRequest) is followed by the further details as key=value pairs.
+//
+// Parameters:
+//   - details: *httpRequestDetails - the details, may be nil
+//
+// Returns:
+//   - string: the formatted details, or an empty string if details is nil
+//
+// Example:
+//
+//	// 192.168.0.1:12345 GET https://example.com/ proto=HTTP/1.1 user_agent=curl/8.0 content_length=12 X-Request-Id=5f322a
+func getHttpRequestDetails(details *httpRequestDetails) string {
+	if details == nil {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString(details.RemoteAddr + " " + details.Method + " " + details.URL)
+
+	write := func(key, value string) {
+		if value != "" {
+			result.WriteString(" " + getFields(map[string]any{key: value}))
+		}
+	}
+	write("proto", details.Proto)
+	write("user_agent", details.UserAgent)
+	if details.ContentLength > 0 {
+		write("content_length", strconv.FormatInt(details.ContentLength, 10))
+	}
+	if len(details.Headers) > 0 {
+		fields := make(map[string]any, len(details.Headers))
+		for name, value := range details.Headers {
+			fields[name] = value
+		}
+		result.WriteString(" " + getFields(fields))
+	}
+
+	return result.String()
+}
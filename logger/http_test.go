@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCaptureHttpBody(t *testing.T) {
+	l := &Logger{Options: Options{HttpBodyMaxBytes: 5}}
+
+	cases := map[string]string{
+		"":           "",
+		"abc":        "abc",
+		"abcde":      "abcde",
+		"abcdefghij": "abcde" + httpBodyTruncatedText,
+	}
+
+	for body, expected := range cases {
+		request, _ := http.NewRequest("POST", "https://example.com", strings.NewReader(body))
+
+		if actual := l.captureHttpBody(request); actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+
+		// The handler can still read the complete body
+		restored, _ := io.ReadAll(request.Body)
+		if string(restored) != body {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", body, string(restored))
+		}
+	}
+
+	// Requests without a body are ignored
+	if actual := l.captureHttpBody(nil); actual != "" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "", actual)
+	}
+	request, _ := http.NewRequest("GET", "https://example.com", nil)
+	if actual := l.captureHttpBody(request); actual != "" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "", actual)
+	}
+}
+
+func TestHttpDetailed(t *testing.T) {
+	request, _ := http.NewRequest("POST", "https://example.com/users?a=1", strings.NewReader("{}"))
+	request.RemoteAddr = "192.168.0.1:12345"
+	request.Header.Set("User-Agent", "curl/8.0")
+	request.Header.Set("X-Request-Id", "5f322a")
+	request.Header.Set("Authorization", "Bearer secret")
+	request.Header.Add("Accept", "text/html")
+	request.Header.Add("Accept", "application/json")
+
+	l := &Logger{
+		Format: []LogFormat{FORMAT_HTTP_DETAILED},
+		Options: Options{
+			HttpHeaders: []string{"x-request-id", "Authorization", "Accept", "X-Missing"},
+			RedactKeys:  []string{"authorization"},
+		},
+	}
+	c := Container{HttpRequest: request}
+
+	expected := `192.168.0.1:12345 POST https://example.com/users?a=1 proto=HTTP/1.1 user_agent=curl/8.0 content_length=2 ` +
+		`Accept="text/html, application/json" Authorization=*** X-Request-Id=5f322a`
+	if actual := l.FormatEntry(c); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	l.Options.OutputFormat = OUTPUT_JSON
+	expected = `{"http_detailed":{"remote_addr":"192.168.0.1:12345","method":"POST","url":"https://example.com/users?a=1","proto":"HTTP/1.1",` +
+		`"user_agent":"curl/8.0","content_length":2,"headers":{"Accept":"text/html, application/json","Authorization":"***","X-Request-Id":"5f322a"}}}`
+	if actual := l.FormatEntry(c); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// The compact format is unchanged
+	l.Format = []LogFormat{FORMAT_HTTP_REQUEST}
+	l.Options.OutputFormat = OUTPUT_TEXT
+	expected = "192.168.0.1:12345 POST https://example.com/users?a=1"
+	if actual := l.FormatEntry(c); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestClientIPHeader(t *testing.T) {
+	request, _ := http.NewRequest("GET", "https://example.com/", nil)
+	request.RemoteAddr = "10.0.0.1:443"
+	request.Header.Set("X-Forwarded-For", " 203.0.113.7 , 10.0.0.2")
+	request.Header.Set("X-Real-IP", "203.0.113.8")
+
+	cases := map[string]string{
+		"":                "10.0.0.1:443",
+		"x-forwarded-for": "203.0.113.7",
+		"X-Real-IP":       "203.0.113.8",
+		"X-Client-IP":     "10.0.0.1:443", // Not present
+	}
+
+	for header, addr := range cases {
+		l := &Logger{Format: []LogFormat{FORMAT_HTTP_REQUEST}, Options: Options{ClientIPHeader: header}}
+
+		expected := addr + " GET https://example.com/"
+		if actual := l.FormatEntry(Container{HttpRequest: request}); expected != actual {
+			t.Errorf("Unexpected result for %q.\nExpected:\n%#v\nGot:\n%#v", header, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Default keys and layout of the NDJSON output
+const (
+	defaultNDJSONMessageKey      = "message"
+	defaultNDJSONLevelKey        = "level"
+	defaultNDJSONTimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// Writes the members of a JSON object in the order they are added.
+//
+// Marshaling a map would sort the keys alphabetically (or randomly for other encoders), so the object
+// is assembled manually in a bytes.Buffer. This guarantees that the keys appear in the order of the
+// format, which keeps consecutive runs diffable byte by byte.
+type jsonObject struct {
+	buf bytes.Buffer
+}
+
+// Adds a member with an already encoded JSON value.
+//
+// Parameters:
+//   - key: string - the key of the member
+//   - raw: []byte - the encoded JSON value
+func (o *jsonObject) addRaw(key string, raw []byte) {
+	if o.buf.Len() == 0 {
+		o.buf.WriteByte('{')
+	} else {
+		o.buf.WriteByte(',')
+	}
+
+	encodedKey, _ := encodeJSON(key)
+	o.buf.Write(encodedKey)
+	o.buf.WriteByte(':')
+	o.buf.Write(raw)
+}
+
+// Adds a member and encodes its value as JSON.
+//
+// If the value cannot be encoded, the error message is added as string instead.
+//
+// Parameters:
+//   - key: string - the key of the member
+//   - value: any - the value of the member
+func (o *jsonObject) add(key string, value any) {
+	raw, err := encodeJSON(value)
+	if err != nil {
+		raw, _ = encodeJSON(err.Error())
+	}
+	o.addRaw(key, raw)
+}
+
+// Encodes a value as compact JSON without escaping HTML characters, so URLs like "/?a=1&b=2" stay readable.
+//
+// Parameters:
+//   - value: any - the value to encode
+//
+// Returns:
+//   - []byte: the encoded value
+//   - error: an error if the value cannot be encoded
+func encodeJSON(value any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// Returns the encoded JSON object.
+//
+// Returns:
+//   - string: the JSON object, "{}" if no member was added
+func (o *jsonObject) String() string {
+	if o.buf.Len() == 0 {
+		return "{}"
+	}
+	return o.buf.String() + "}"
+}
+
+// Returns the value of an option or its default if the option is empty.
+//
+// Parameters:
+//   - value: string - the configured value
+//   - fallback: string - the default value
+//
+// Returns:
+//   - string: the value to use
+func stringOrDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// Default JSON keys of the log fields
+var jsonFieldNames = map[LogFormat]string{
+	FORMAT_STATUS:          "status",
+	FORMAT_PRE_TEXT:        "pre_text",
+	FORMAT_ID:              "id",
+	FORMAT_SOURCE:          "source",
+	FORMAT_INFO:            "info",
+	FORMAT_DATA:            "data",
+	FORMAT_ERROR:           "error",
+	FORMAT_PROCESSING_TIME: "processing_time_ms",
+	FORMAT_TIMESTAMP:       "timestamp",
+	FORMAT_HTTP_REQUEST:    "http_request",
+	FORMAT_PROCESSED_DATA:  "processed_data",
+	FORMAT_GOROUTINES:      "goroutines",
+	FORMAT_MEMSTATS:        "memstats",
+	FORMAT_FIELDS:          "fields",
+	FORMAT_CALLER:          "caller",
+	FORMAT_HTTP_BODY:       "http_body",
+	FORMAT_HOSTNAME:        "hostname",
+	FORMAT_PID:             "pid",
+	FORMAT_HTTP_DETAILED:   "http_detailed",
+	FORMAT_HTTP_STATUS:     "http_status",
+	FORMAT_RESPONSE_BYTES:  "response_bytes",
+	FORMAT_SEQ:             "seq",
+}
+
+// Returns the JSON key of a log field for the configured output format.
+//
+// OUTPUT_NDJSON follows Elastic/OpenSearch conventions and writes the status to Options.NDJSONLevelKey
+// (default "level"), the info to Options.NDJSONMessageKey (default "message") and the timestamp to "@timestamp".
+// Other keys can be renamed with Options.JSONFieldNames.
+//
+// Parameters:
+//   - formatItem: LogFormat - the log field
+//   - output: OutputFormat - the output format e.g. Options.OutputFormat
+//
+// Returns:
+//   - string: the JSON key
+func (l *Logger) jsonKey(formatItem LogFormat, output OutputFormat) string {
+	if output == OUTPUT_NDJSON {
+		switch formatItem {
+		case FORMAT_STATUS:
+			return stringOrDefault(l.Options.NDJSONLevelKey, defaultNDJSONLevelKey)
+		case FORMAT_INFO:
+			return stringOrDefault(l.Options.NDJSONMessageKey, defaultNDJSONMessageKey)
+		case FORMAT_TIMESTAMP:
+			return "@timestamp"
+		}
+	}
+	if custom, ok := lookupCustomFormat(formatItem); ok {
+		return l.renameJSONKey(strings.ToLower(custom.name))
+	}
+	return l.renameJSONKey(jsonFieldNames[formatItem])
+}
+
+// Renames a default JSON key according to Options.JSONFieldNames.
+//
+// Parameters:
+//   - key: string - the default key e.g. "status"
+//
+// Returns:
+//   - string: the configured name e.g. "level", or the default key if it is not mapped
+func (l *Logger) renameJSONKey(key string) string {
+	return stringOrDefault(l.Options.JSONFieldNames[key], key)
+}
+
+// Formats the log entry as a single line JSON object.
+//
+// The members are written in the order of the configured log format items and empty fields are skipped,
+// just like in the text output. With OUTPUT_JSON the keys are "status", "pre_text", "id", "source", "info",
+// "data", "error" (plus "errors" for Container.Errors and "error_chain" for Options.ExpandErrorChain), "processing_time_ms", "timestamp", "http_request",
+// "processed_data", "goroutines", "memstats", "fields", "caller", "http_body", "hostname",
+// "pid", "http_detailed", "http_status", "response_bytes" and "seq" (plus the lowercase name of items
+// registered with RegisterFormat); the timestamp is formatted with Options.TimestampLayout (default RFC3339).
+// The keys can be renamed with Options.JSONFieldNames e.g. "status" to "level".
+//
+// With OUTPUT_NDJSON the status is written in lowercase to Options.NDJSONLevelKey (default "level"),
+// Container.Info to Options.NDJSONMessageKey (default "message") and the timestamp to "@timestamp" using
+// Options.NDJSONTimestampLayout (default ISO8601 with milliseconds).
+//
+// Parameters:
+//   - c: *Container - the log entry container
+//   - format: []LogFormat - the format items e.g. Logger.Format
+//   - output: OutputFormat - OUTPUT_JSON or OUTPUT_NDJSON
+//
+// Returns:
+//   - string: the JSON object
+//
+// Example:
+//
+//	// OUTPUT_JSON:   {"timestamp":"2023-06-01T12:00:00+02:00","status":"INFO","info":"user created"}
+//	// OUTPUT_NDJSON: {"@timestamp":"2023-06-01T12:00:00.000+02:00","level":"info","message":"user created"}
+func (l *Logger) formatJSON(c *Container, format []LogFormat, output OutputFormat) string {
+	var obj jsonObject
+	ndjson := output == OUTPUT_NDJSON
+
+	for _, formatItem := range format {
+		key := l.jsonKey(formatItem, output)
+
+		switch formatItem {
+		case FORMAT_STATUS:
+			if str := logStatustoString[c.Status]; str != "" {
+				if ndjson {
+					str = strings.ToLower(str)
+				}
+				obj.add(key, str)
+			}
+		case FORMAT_PRE_TEXT:
+			if c.PreText != "" {
+				obj.add(key, c.PreText)
+			}
+		case FORMAT_ID:
+			if c.Id != "" {
+				obj.add(key, c.Id)
+			}
+		case FORMAT_SOURCE:
+			if c.Source != "" {
+				obj.add(key, c.Source)
+			}
+		case FORMAT_INFO:
+			if c.Info != "" {
+				obj.add(key, c.Info)
+			}
+		case FORMAT_DATA:
+			if c.Data != "" {
+				obj.add(key, c.Data)
+			}
+		case FORMAT_ERROR:
+			if str := l.getError(c); str != "" {
+				obj.add(key, str)
+			}
+			if l.Options.ExpandErrorChain && c.Err != nil {
+				obj.add(l.renameJSONKey(jsonFieldNames[FORMAT_ERROR]+"_chain"), errorChain(c.Err))
+			}
+			if errs := errorMessages(c.Errors); len(errs) > 0 {
+				obj.add(l.renameJSONKey(jsonFieldNames[FORMAT_ERROR]+"s"), errs)
+			}
+		case FORMAT_PROCESSING_TIME:
+			if c.ProcessingTime != 0 {
+				obj.add(key, float64(c.ProcessingTime.Microseconds())/1000.0)
+			}
+		case FORMAT_TIMESTAMP:
+			layout := l.timestampLayout()
+			if ndjson {
+				layout = stringOrDefault(l.Options.NDJSONTimestampLayout, defaultNDJSONTimestampLayout)
+			}
+			obj.add(key, c.Timestamp.Format(layout))
+		case FORMAT_HTTP_REQUEST:
+			if str := l.getHttpRequest(c.HttpRequest); str != "" {
+				obj.add(key, str)
+			}
+		case FORMAT_PROCESSED_DATA:
+			raw, err := encodeJSON(l.normalizeData(c.ProcessedData))
+			if err != nil {
+				l.reportError("serialize processed data", err)
+				raw, _ = encodeJSON(unserializablePlaceholder(err))
+			}
+			obj.addRaw(key, raw)
+		case FORMAT_GOROUTINES:
+			obj.add(key, runtime.NumGoroutine())
+		case FORMAT_MEMSTATS:
+			obj.add(key, l.getMemStats())
+		case FORMAT_HTTP_BODY:
+			if c.HttpBody != "" {
+				obj.add(key, c.HttpBody)
+			}
+		case FORMAT_CALLER:
+			if c.Caller != "" {
+				obj.add(key, c.Caller)
+			}
+		case FORMAT_FIELDS:
+			if len(c.Fields) > 0 {
+				obj.add(key, l.normalizeData(c.Fields))
+			}
+		case FORMAT_HTTP_DETAILED:
+			if details := l.getHttpRequestDetails(c.HttpRequest); details != nil {
+				obj.add(key, details)
+			}
+		case FORMAT_HTTP_STATUS:
+			if c.HttpStatus != 0 {
+				obj.add(key, c.HttpStatus)
+			}
+		case FORMAT_RESPONSE_BYTES:
+			if c.ResponseBytes != 0 {
+				obj.add(key, c.ResponseBytes)
+			}
+		case FORMAT_HOSTNAME:
+			if l.hostname != "" {
+				obj.add(key, l.hostname)
+			}
+		case FORMAT_PID:
+			obj.add(key, os.Getpid())
+		case FORMAT_SEQ:
+			if c.seq != 0 {
+				obj.add(key, c.seq)
+			}
+		default:
+			if custom, ok := lookupCustomFormat(formatItem); ok {
+				if str := custom.render(*c); str != "" {
+					obj.add(key, str)
+				}
+			}
+		}
+	}
+
+	return obj.String()
+}
+
+// Returns the messages of all non-nil errors, with combined errors (e.g. from errors.Join) split up.
+//
+// Parameters:
+//   - errs: []error - the errors
+//
+// Returns:
+//   - []string: the error messages
+func errorMessages(errs []error) []string {
+	var messages []string
+	for _, err := range flattenErrors(errs) {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
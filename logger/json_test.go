@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFormatNDJSON(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	l := &Logger{
+		Format: []LogFormat{FORMAT_TIMESTAMP, FORMAT_STATUS, FORMAT_ID, FORMAT_INFO, FORMAT_ERROR, FORMAT_PROCESSING_TIME},
+		Options: Options{
+			OutputFormat:     OUTPUT_NDJSON,
+			NDJSONMessageKey: "msg",
+		},
+	}
+
+	container := Container{
+		Timestamp:      ts,
+		Status:         STATUS_WARN,
+		Info:           "disk \"almost\" full",
+		Errors:         []error{errors.New("first"), nil, errors.New("second")},
+		ProcessingTime: 1500 * time.Microsecond,
+	}
+
+	expected := `{"@timestamp":"2023-06-01T12:00:00.000Z","level":"warn","msg":"disk \"almost\" full","errors":["first","second"],"processing_time_ms":1.5}`
+	actual := l.formatJSON(&container, l.Format, l.Options.OutputFormat)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestFormatNDJSONKeepsFormatOrder(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	request, _ := http.NewRequest("GET", "https://example.com/?a=1&b=2", nil)
+	request.RemoteAddr = "192.168.0.1:12345"
+
+	l := &Logger{
+		Format: []LogFormat{
+			FORMAT_PROCESSED_DATA,
+			FORMAT_ID,
+			FORMAT_TIMESTAMP,
+			FORMAT_STATUS,
+			FORMAT_HTTP_REQUEST,
+			FORMAT_SOURCE,
+			FORMAT_PRE_TEXT,
+			FORMAT_ERROR,
+			FORMAT_DATA,
+			FORMAT_INFO,
+			FORMAT_PROCESSING_TIME,
+		},
+		Options: Options{OutputFormat: OUTPUT_NDJSON},
+	}
+
+	container := Container{
+		Timestamp:      ts,
+		Status:         STATUS_ERROR,
+		PreText:        "SERVER1",
+		HttpRequest:    request,
+		Id:             "5f322ac4ba",
+		Source:         "handler/user",
+		Info:           "This is an information message",
+		Data:           "233",
+		Error:          "something went wrong",
+		ProcessingTime: time.Millisecond,
+		ProcessedData:  map[string]any{"zeta": 1, "alpha": 2},
+	}
+
+	expected := `{"processed_data":{"alpha":2,"zeta":1},"id":"5f322ac4ba","@timestamp":"2023-06-01T12:00:00.000Z","level":"error",` +
+		`"http_request":"192.168.0.1:12345 GET https://example.com/?a=1&b=2","source":"handler/user","pre_text":"SERVER1",` +
+		`"error":"something went wrong","data":"233","message":"This is an information message","processing_time_ms":1}`
+
+	for i := 0; i < 10; i++ {
+		actual := l.formatJSON(&container, l.Format, l.Options.OutputFormat)
+		if actual != expected {
+			t.Fatalf("Unexpected result.\nExpected:\n%s\nGot:\n%s", expected, actual)
+		}
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_TIMESTAMP, FORMAT_STATUS, FORMAT_PRE_TEXT, FORMAT_INFO, FORMAT_ERROR, FORMAT_DATA},
+		Options: Options{OutputFormat: OUTPUT_JSON},
+	}
+
+	container := Container{
+		Timestamp: ts,
+		Status:    STATUS_ERROR,
+		Info:      "user not created",
+		Error:     "duplicate key",
+		Errors:    []error{errors.New("first"), errors.Join(errors.New("second"), errors.New("third"))},
+	}
+
+	expected := `{"timestamp":"2023-06-01T12:00:00Z","status":"ERROR","info":"user not created","error":"duplicate key","errors":["first","second","third"]}`
+	actual := l.formatJSON(&container, l.Format, l.Options.OutputFormat)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
+
+func TestFormatJSONFields(t *testing.T) {
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_INFO, FORMAT_FIELDS},
+		Options: Options{OutputFormat: OUTPUT_JSON},
+	}
+
+	container := Container{
+		Info:   "user created",
+		Fields: map[string]any{"user_id": 42, "request_id": "abc"},
+	}
+
+	expected := `{"info":"user created","fields":{"request_id":"abc","user_id":42}}`
+	actual := l.formatJSON(&container, l.Format, l.Options.OutputFormat)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
+
+func TestFormatJSONErr(t *testing.T) {
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_INFO, FORMAT_ERROR},
+		Options: Options{OutputFormat: OUTPUT_JSON},
+	}
+
+	// Err is preferred over the Error string
+	container := Container{Info: "query failed", Error: "ignored", Err: errors.New("connection refused")}
+
+	expected := `{"info":"query failed","error":"connection refused"}`
+	actual := l.formatJSON(&container, l.Format, l.Options.OutputFormat)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestFormatJSONFieldNames(t *testing.T) {
+	l := &Logger{
+		Format: []LogFormat{FORMAT_STATUS, FORMAT_SOURCE, FORMAT_INFO, FORMAT_ERROR},
+		Options: Options{
+			OutputFormat:   OUTPUT_JSON,
+			JSONFieldNames: map[string]string{"status": "level", "info": "message", "errors": "causes"},
+		},
+	}
+
+	container := Container{
+		Status: STATUS_WARN,
+		Source: "handler/user",
+		Info:   "retrying",
+		Error:  "timeout",
+		Errors: []error{errors.New("first")},
+	}
+
+	// Unmapped keys keep their defaults
+	expected := `{"level":"WARN","source":"handler/user","message":"retrying","error":"timeout","causes":["first"]}`
+	actual := l.formatJSON(&container, l.Format, l.Options.OutputFormat)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
+
+func TestFormatJSONSeq(t *testing.T) {
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_SEQ, FORMAT_INFO},
+		Options: Options{OutputFormat: OUTPUT_JSON},
+	}
+
+	container := Container{Info: "user created", seq: 42}
+
+	expected := `{"seq":42,"info":"user created"}`
+	actual := l.formatJSON(&container, l.Format, l.Options.OutputFormat)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
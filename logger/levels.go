@@ -0,0 +1,54 @@
+package logger
+
+// Logs a TRACE entry with the given info.
+//
+// It is a shortcut for Entry with a Container holding only the status and the info.
+// Use Entry directly to set further fields.
+//
+// Parameters:
+//   - info: string - the message of the entry
+func (l *Logger) Trace(info string) {
+	l.entry(Container{Status: STATUS_TRACE, Info: info}, 1)
+}
+
+// Logs an INFO entry with the given info, see Trace.
+//
+// Parameters:
+//   - info: string - the message of the entry
+func (l *Logger) Info(info string) {
+	l.entry(Container{Status: STATUS_INFO, Info: info}, 1)
+}
+
+// Logs a WARN entry with the given info, see Trace.
+//
+// Parameters:
+//   - info: string - the message of the entry
+func (l *Logger) Warn(info string) {
+	l.entry(Container{Status: STATUS_WARN, Info: info}, 1)
+}
+
+// Logs an ERROR entry with the given info and error.
+//
+// It is a shortcut for Entry with a Container holding only the status, the info and the error.
+//
+// Parameters:
+//   - info: string - the message of the entry
+//   - err: error - the error which occurred, may be nil
+//
+// Example:
+//
+//	if err := db.Ping(); err != nil {
+//	    appLogger.Error("database unreachable", err)
+//	}
+func (l *Logger) Error(info string, err error) {
+	l.entry(Container{Status: STATUS_ERROR, Info: info, Err: err}, 1)
+}
+
+// Logs a FATAL entry with the given info and error, see Error.
+//
+// Parameters:
+//   - info: string - the message of the entry
+//   - err: error - the error which occurred, may be nil
+func (l *Logger) Fatal(info string, err error) {
+	l.entry(Container{Status: STATUS_FATAL, Info: info, Err: err}, 1)
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+func TestLevelShortcuts(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_CALLER, FORMAT_INFO, FORMAT_ERROR}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+	}, Container{Status: STATUS_INFO, Info: "started", Caller: "main.go:1"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.Trace("trace")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error", errors.New("timeout"))
+	logger.Fatal("fatal", nil)
+	logger.Close()
+
+	expected := "INFO main.go:1 started\n" +
+		fmt.Sprintf("TRACE levels_test.go:%d trace\n", line+1) +
+		fmt.Sprintf("INFO levels_test.go:%d info\n", line+2) +
+		fmt.Sprintf("WARN levels_test.go:%d warn\n", line+3) +
+		fmt.Sprintf("ERROR levels_test.go:%d error timeout\n", line+4) +
+		fmt.Sprintf("FATAL levels_test.go:%d fatal\n", line+5)
+	if actual := capturedOutput.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestExitOnFatal(t *testing.T) {
+	defer func(original func(int)) { exitFunc = original }(exitFunc)
+
+	var exitCode int
+	var outputAtExit string
+	var capturedOutput bytes.Buffer
+
+	exitFunc = func(code int) {
+		exitCode = code
+		outputAtExit = capturedOutput.String()
+	}
+
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		OutputToStdout:    true,
+		Stdout:            &capturedOutput,
+		ChannelBufferSize: 10,
+		ExitOnFatal:       true,
+		FatalExitCode:     3,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	logger.Error("retrying", nil)
+	logger.Fatal("giving up", nil)
+
+	if exitCode != 3 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 3, exitCode)
+	}
+
+	// Everything was written before the process would have exited
+	expected := "INFO started\nERROR retrying\nFATAL giving up\n"
+	if outputAtExit != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, outputAtExit)
+	}
+}
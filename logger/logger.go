@@ -1,11 +1,23 @@
 package logger
 
 import (
+	"bufio"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,14 +26,190 @@ type Logger struct {
 	LogChan        chan Container
 	StatusCounters map[LogStatus]int
 	Options        Options
+
+	formatMu    sync.RWMutex // Guards Format, which may be replaced by SetFormat while processLogs reads it
+	minStatusMu sync.RWMutex // Guards Options.MinStatus, which may be changed by SetMinStatus while processLogs reads it
+	countersMu  sync.RWMutex // Guards StatusCounters, which are incremented by processLogs and read by the caller
+
+	aggregates     map[string]*aggregate // Entries collected during the current aggregation window
+	aggregateOrder []string              // Keys of the aggregates in order of their first occurrence
+
+	fileMu   sync.Mutex    // Guards the cached log file handle
+	file     logFile       // Cached handle of the current log file
+	fileName string        // Path of the current log file
+	fileSize int64         // Size of the current log file, tracked to avoid a stat per line
+	fileBuf  *bufio.Writer // Buffers writes to the log file, flushed per line, per batch or every Options.FlushInterval
+	batching bool          // Set while processLogs drains a batch of entries, the log file is then flushed once after the batch
+	fs       fileSystem    // File operations, the real file system if nil
+	writeErr error         // Last error which occurred while opening or writing the log file
+
+	sighup chan os.Signal // Receives SIGHUP if Options.ReopenOnSIGHUP is set
+	syslog syslogWriter   // Connection to the syslog daemon if Options.OutputToSyslog is set
+
+	webhookQueue chan []byte   // Payloads waiting to be posted to Options.WebhookURL
+	webhookDone  chan struct{} // Closed when the webhook worker has returned
+
+	stages       []Stage        // Processing pipeline, built on first use
+	dataRedactor *regexp.Regexp // Finds sensitive values in Container.Data, nil if disabled
+
+	sampleSeen map[LogStatus]int // Number of entries seen per status for sampling
+	sampledOut int               // Number of entries discarded by sampling
+
+	rateLimiters map[LogStatus]*rateLimiter // Token buckets of the statuses with a rate limit
+	repeat       *repeatState               // Last written line if Options.RepeatWindow is set
+
+	worstStatus atomic.Int32  // Highest status logged so far
+	dropped     atomic.Int64  // Number of entries dropped because the log channel was full
+	lateEntries atomic.Int64  // Number of entries ignored because they were passed to Entry after Close
+	seq         atomic.Uint64 // Sequence number of the last written entry, see FORMAT_SEQ
+
+	colorOnce sync.Once // Guards color
+	color     bool      // Set if the status is colored on STDOUT, see colorStdout
+
+	hostname string // Cached FORMAT_HOSTNAME value, read by NewLogger
+
+	memStatsMu   sync.Mutex // Guards the memStats cache, FormatEntry formats concurrently to processLogs
+	memStats     string     // Cached FORMAT_MEMSTATS value
+	memStatsRead time.Time  // Point in time when memStats was read
+
+	recent recentRing // Most recently written lines if Options.RecentEntries is set
+
+	subMu       sync.Mutex                          // Guards subscribers
+	subscribers map[<-chan Container]chan Container // Channels of the subscribers
+	subClosed   bool                                // Set by unsubscribeAll, later subscriptions get a closed channel
+
+	gzipOutputs []*gzip.Writer // Gzip streams wrapping Options.GzipOutputs, written and flushed under processMu
+	gzipPending bool           // Set if lines were written to the gzip streams since their last flush
+	processMu   sync.Mutex     // Serializes entry processing of processLogs and synchronous Entry calls, see Options.Synchronous
+
+	started   time.Time     // Point in time when the logger was created
+	done      chan struct{} // Closed when processLogs has returned
+	closeOnce sync.Once     // Guards Close
+	closeMu   sync.RWMutex  // Guards closed against concurrent Entry calls
+	closed    atomic.Bool   // Set by Close, further entries are ignored (written under closeMu, read lock-free as fast path)
+	closeErr  error         // Result of Close
 }
 
 type Options struct {
-	OutputToStdout   bool   // Set true if logs should be routed to STDOUT
-	OutputToFile     bool   // Set true if logs should be routed to file
-	OutputFolderPath string // Folder in which logs shall be stored
+	OutputToStdout        bool                 // Set true if logs should be routed to STDOUT
+	Stdout                io.Writer            // Destination of the STDOUT output (default os.Stdout), e.g. a bytes.Buffer in tests
+	Outputs               []io.Writer          // Additional destinations e.g. a network connection or an in-memory buffer, each receives every line
+	GzipOutputs           []io.Writer          // Additional destinations which receive every line gzip compressed e.g. a stream to remote storage (flushed after every batch of entries, or by Options.FlushInterval if set)
+	RecentEntries         int                  // Keep the last N formatted lines in memory, see Logger.Recent (0 disables)
+	OutputToSyslog        bool                 // Set true if logs should be routed to the system log (Unix only)
+	SyslogNetwork         string               // Network of the syslog daemon e.g. "udp" (default local daemon)
+	SyslogAddr            string               // Address of the syslog daemon e.g. "localhost:514" (default local daemon)
+	SyslogTag             string               // Tag of the syslog messages (default program name)
+	WebhookURL            string               // POST entries as JSON to this URL e.g. an alerting webhook (empty disables the webhook)
+	WebhookMinStatus      LogStatus            // Only entries with at least this status are posted to the webhook
+	WebhookTimeout        time.Duration        // Timeout of a webhook request (default 5s)
+	ColorStdout           bool                 // Color the status on STDOUT per level (WARN yellow, ERROR/FATAL red, TRACE dim) if it is a terminal and NO_COLOR is not set
+	OutputToFile          bool                 // Set true if logs should be routed to file
+	OutputFolderPath      string               // Folder in which logs shall be stored
+	FileFormat            []LogFormat          // Format of the log file entries, e.g. more fields than on STDOUT (nil uses the format of the logger)
+	FileOutputFormat      OutputFormat         // How log file entries are serialized, only used if FileFormat is set (default OUTPUT_TEXT)
+	FilePrefix            string               // Prefix of the log file names e.g. "access" for "access_YYYY_MM_DD.log", allows several loggers to share a folder
+	CreateFolderIfMissing bool                 // Create the output folder including its parents on startup instead of failing if it does not exist
+	FolderMode            os.FileMode          // Permissions of folders created by CreateFolderIfMissing (default 0755, reduced by the umask)
+	FileMode              os.FileMode          // Permissions of created log files e.g. 0600 or 0664 (default 0644, reduced by the umask; existing files keep their mode)
+	MaxFileSizeBytes      int64                // Rotate the log file to YYYY_MM_DD.1.log, .2.log, ... before it exceeds this size (0 disables size rotation)
+	MaxRetentionDays      int                  // Remove log files dated more than this many days ago on startup and when a new day starts (0 keeps all files)
+	CurrentLogLink        bool                 // Maintain "current.log" in the output folder as link to the active log file, e.g. for tail -F
+	FlushInterval         time.Duration        // Buffer log file writes and flush them at this interval, on Flush and on Close (0 writes every line directly)
+	ErrorHandler          func(error)          // Receives internal errors like failed log file writes (default prints them to STDOUT)
+	TimestampMode         TimestampMode        // How FORMAT_TIMESTAMP is rendered (default TIMESTAMP_ABSOLUTE)
+	TimestampLayout       string               // Layout of absolute timestamps as used by time.Format (default time.RFC3339)
+	NanoTimestamps        bool                 // Use time.RFC3339Nano as timestamp layout, so rapid entries get distinct timestamps (ignored if TimestampLayout is set)
+	ProcessingTimeUnit    ProcessingTimeUnit   // Unit of FORMAT_PROCESSING_TIME in the text output and of durations in ProcessedData and Fields (default TIME_UNIT_MS)
+	UseUTC                bool                 // Convert timestamps to UTC before formatting them and before deriving the daily log file name
+	AggregateWindow       time.Duration        // Interval in which entries with identical AggregateKeys are summarized (0 disables aggregation)
+	AggregateKeys         []LogFormat          // Fields which identify identical entries e.g. FORMAT_ERROR and FORMAT_SOURCE (STATUS, PRE_TEXT, ID, SOURCE, INFO, DATA or ERROR)
+	ReopenOnSIGHUP        bool                 // Set true to reopen the log file on SIGHUP (installs a process-wide signal handler)
+	SampleRates           map[LogStatus]int    // Emit only 1 of every N entries per status (unspecified statuses default to 1 = keep all, ERROR and FATAL are never sampled)
+	RateLimits            map[LogStatus]int    // Maximum entries per second per status, further entries are suppressed and reported as "N messages suppressed"
+	RepeatWindow          time.Duration        // Suppress consecutive identical lines (ignoring the timestamp) and write "last message repeated N times" at most once per window (0 disables)
+	MinStatus             LogStatus            // Skip entries whose status is below this threshold, they are neither counted nor written (default STATUS_TRACE = keep all)
+	Filter                func(Container) bool // Skip entries for which the function returns false, they are neither counted nor written (nil keeps all)
+	MemStatsInterval      time.Duration        // How long FORMAT_MEMSTATS values are cached (default 10s)
+	CallerSkip            int                  // Additional stack frames to skip for FORMAT_CALLER, e.g. 1 if Entry is called through a wrapper function
+	HttpBodyMaxBytes      int                  // Maximum number of request body bytes recorded by FORMAT_HTTP_BODY (default 4096)
+	HttpHeaders           []string             // Request headers logged by FORMAT_HTTP_DETAILED e.g. "X-Request-Id", headers in RedactKeys are masked
+	ClientIPHeader        string               // Header holding the client address behind a proxy, "X-Forwarded-For" (first address) or e.g. "X-Real-IP" (default RemoteAddr, only set it if the proxy is trusted)
+	ContextIdKey          any                  // Key of the request/trace id in the context passed to EntryCtx, the value must be a string
+	SkipCanceledContext   bool                 // Skip entries passed to EntryCtx if their context is already canceled
+	ChannelBufferSize     int                  // Capacity of the log channel (default 0 = unbuffered, Entry blocks until the entry is picked up)
+	DropWhenFull          bool                 // Set true to drop entries instead of blocking when the log channel is full (see DroppedCount, every drop is reported as ErrLogQueueFull)
+	Synchronous           bool                 // Process and write entries on the goroutine calling Entry instead of the log channel (deterministic, concurrent calls are serialized by a mutex)
+	SummaryOnClose        bool                 // Set true to write a summary (entries, per-status counts, sampled out and dropped entries, uptime) on Close
+	ExitOnFatal           bool                 // Close the logger and exit the process after a STATUS_FATAL entry has been written
+	FatalExitCode         int                  // Exit code used by ExitOnFatal (default 1)
+	RedactDataKeys        []string             // Keys whose values are masked in Container.Data e.g. "password", "token", "secret" (best-effort)
+	RedactKeys            []string             // Keys whose values are masked in Container.Fields, ProcessedData and HTTP headers e.g. "Authorization", "Cookie" (case-insensitive)
+	Pipeline              []Stage              // Custom processing stages, executed after the built-in filters (see Logger.pipeline)
+	Hooks                 []func(Container)    // Called for every entry after formatting and before writing, in the logging goroutine (must be fast and non-blocking)
+
+	ErrorPrecedence       ErrorPrecedence   // How Container.Error and Container.Err are combined when both are set (default ERROR_PREFER_ERR)
+	ExpandErrorChain      bool              // Render every layer of the wrapped Container.Err joined by ": " (JSON: additional "error_chain" array)
+	EmptyFieldPlaceholder string            // Rendered instead of an empty field e.g. "-" to keep columns aligned (default: empty fields are skipped)
+	FieldSeparator        string            // Separator between the fields of the text output e.g. "\t" or " | " (default a single space)
+	LinePrefix            string            // Written before every line of the text output e.g. "[app] " (JSON output is unaffected)
+	LineSuffix            string            // Written after every line of the text output e.g. a delimiter for a custom parser (JSON output is unaffected)
+	LineEnding            string            // Terminates every line written to the log file, STDOUT and Options.Outputs e.g. "\r\n" for Windows log viewers, also replaces line breaks inside entries (default "\n")
+	ContinuationMode      ContinuationMode  // How continuation lines of multi-line entries are prefixed (default CONTINUATION_NONE)
+	EscapeNewlines        bool              // Escape line breaks in the fields of the text output as \n and \r, so every entry is exactly one line (JSON output is unaffected)
+	CompactProcessedData  bool              // Render FORMAT_PROCESSED_DATA as single-line JSON without the ">Processed Data:" banner (default indented JSON)
+	OutputFormat          OutputFormat      // How entries are serialized (default OUTPUT_TEXT)
+	JSONFieldNames        map[string]string // Renames JSON keys by their default name e.g. {"status": "level", "info": "message"} (unmapped keys keep their default)
+	NDJSONMessageKey      string            // Key of Container.Info in OUTPUT_NDJSON (default "message")
+	NDJSONLevelKey        string            // Key of Container.Status in OUTPUT_NDJSON (default "level")
+	NDJSONTimestampLayout string            // Layout of the "@timestamp" value in OUTPUT_NDJSON (default ISO8601 with milliseconds)
 }
 
+// The error precedence defines which error is logged if both Container.Error and Container.Err are set
+type ErrorPrecedence int
+
+const (
+	ERROR_PREFER_ERR ErrorPrecedence = iota // Only Container.Err is logged
+	ERROR_CONCAT                            // Both are logged as "<Error>: <Err>"
+)
+
+// The continuation mode defines how continuation lines of a multi-line entry (e.g. the indented processed data) are prefixed,
+// so they stay associated with their header line when the output is filtered with grep or similar tools
+type ContinuationMode int
+
+const (
+	CONTINUATION_NONE   ContinuationMode = iota // Continuation lines are written unchanged
+	CONTINUATION_MARKER                         // Continuation lines are prefixed with "| "
+	CONTINUATION_ID                             // Continuation lines are prefixed with the entry's Id e.g. "5f322ac4ba | " (falls back to "| ")
+)
+
+// The unit in which FORMAT_PROCESSING_TIME is rendered in the text output
+type ProcessingTimeUnit int
+
+const (
+	TIME_UNIT_MS ProcessingTimeUnit = iota // Milliseconds with two decimal places e.g. [1.50 ms]
+	TIME_UNIT_NS                           // Nanoseconds e.g. [1500000 ns]
+	TIME_UNIT_US                           // Microseconds with two decimal places e.g. [1500.00 µs]
+	TIME_UNIT_S                            // Seconds with two decimal places e.g. [0.00 s]
+)
+
+// The output format defines how a log entry is serialized
+type OutputFormat int
+
+const (
+	OUTPUT_TEXT   OutputFormat = iota // Space separated fields in the order of the format
+	OUTPUT_NDJSON                     // One JSON object per line following Elastic/OpenSearch conventions
+	OUTPUT_JSON                       // One JSON object per line with the plain field names e.g. "status", "info", "timestamp"
+)
+
+// The timestamp mode defines how the FORMAT_TIMESTAMP field is rendered
+type TimestampMode int
+
+const (
+	TIMESTAMP_ABSOLUTE TimestampMode = iota // Absolute point in time e.g. 2023-06-01T12:00:00+02:00
+	TIMESTAMP_RELATIVE                      // Humanized age at print time e.g. 2s ago
+)
+
 type Container struct {
 	Status         LogStatus
 	PreText        string
@@ -30,10 +218,20 @@ type Container struct {
 	Info           string
 	Data           string
 	Error          string
+	Err            error   // Typed error, preferred over Error by default (see Options.ErrorPrecedence)
+	Errors         []error // Multiple errors of one logical event, rendered as numbered list after Error
 	ProcessingTime time.Duration
 	Timestamp      time.Time
 	HttpRequest    *http.Request
 	ProcessedData  any
+	Fields         map[string]any // Structured context e.g. {"user_id": 42}, rendered by FORMAT_FIELDS
+	Caller         string         // Source location of the Entry call e.g. "main.go:42", set by Entry if FORMAT_CALLER is used
+	HttpBody       string         // Body of HttpRequest, recorded by Entry if FORMAT_HTTP_BODY is used
+	HttpStatus     int            // Status code of the HTTP response e.g. 200, set by HTTPMiddleware (0 is omitted)
+	ResponseBytes  int64          // Size of the HTTP response body in bytes, set by HTTPMiddleware (0 is omitted)
+
+	flushed chan struct{} // Set on the sentinel enqueued by Flush, closed when it is reached
+	seq     uint64        // Sequence number assigned when the entry is written, see FORMAT_SEQ
 }
 
 // Creates a new Logger instance with the specified ontent.
@@ -41,27 +239,104 @@ type Container struct {
 // Parameters:
 //   - format: []LogFormat a collection of the desired format (order will be considered in logs)
 //   - opt: Options options
-//   - firstEntry: Container which has the first entry message (when logger starts) defined
+//   - firstEntry: Container which has the first entry message (when logger starts) defined, nothing is logged if it is the zero Container
 //
 // Returns:
 //   - *Logger: the created Logger instance
+//   - error: ErrInvalidFormat, ErrInvalidSeverity, ErrOutputPathNotDirectory or ErrFolderNotWritable (check with errors.Is) if the
+//     configuration is invalid, or the underlying error if the output folder cannot be accessed. The output folder is only
+//     checked if Options.OutputToFile is set; with Options.CreateFolderIfMissing a missing folder is created first
 func NewLogger(format []LogFormat, opt Options, firstEntry Container) (*Logger, error) {
+	if opt.ChannelBufferSize < 0 {
+		opt.ChannelBufferSize = 0
+	}
+
 	logger := &Logger{
 		Format:  format,
-		LogChan: make(chan Container),
+		LogChan: make(chan Container, opt.ChannelBufferSize),
 		// Initialize the LevelCounters map
 		StatusCounters: make(map[LogStatus]int),
 		Options:        opt,
+		started:        time.Now(),
+		done:           make(chan struct{}),
 	}
 
-	_, err := checkWritePermission(opt.OutputFolderPath)
-	if err != nil {
+	if err := validateFormat(format); err != nil {
 		return nil, err
 	}
+	if err := validateFormat(opt.FileFormat); err != nil {
+		return nil, fmt.Errorf("file format: %w", err)
+	}
+	logger.warnDuplicateFormatItems(format)
+	logger.warnDuplicateFormatItems(opt.FileFormat)
+
+	// Read the hostname once instead of calling into the OS per entry, an unknown hostname skips the field
+	logger.hostname, _ = os.Hostname()
+
+	for status := range opt.SampleRates {
+		if logStatustoString[status] == "" {
+			return nil, fmt.Errorf("%w in sample rates: %d", ErrInvalidSeverity, status)
+		}
+	}
+
+	for status := range opt.RateLimits {
+		if logStatustoString[status] == "" {
+			return nil, fmt.Errorf("%w in rate limits: %d", ErrInvalidSeverity, status)
+		}
+	}
+
+	// Only fields with a label can identify aggregated entries
+	for _, item := range opt.AggregateKeys {
+		if aggregateKeyLabels[item] == "" {
+			return nil, fmt.Errorf("%w in aggregate keys: %s", ErrInvalidFormat, item)
+		}
+	}
+
+	// A logger without file output does not touch the file system at all
+	if opt.OutputToFile {
+		if opt.CreateFolderIfMissing && opt.OutputFolderPath != "" {
+			if err := logger.fileSystem().MkdirAll(opt.OutputFolderPath, logger.folderMode()); err != nil {
+				return nil, err
+			}
+		}
+
+		if opt.OutputFolderPath != "" {
+			if info, err := logger.fileSystem().Stat(opt.OutputFolderPath); err == nil && !info.IsDir() {
+				return nil, fmt.Errorf("%w: %s", ErrOutputPathNotDirectory, opt.OutputFolderPath)
+			}
+		}
+
+		writable, err := checkWritePermission(logger.fileSystem(), opt.OutputFolderPath)
+		if err != nil {
+			return nil, err
+		}
+		if !writable {
+			return nil, fmt.Errorf("%w: %s", ErrFolderNotWritable, opt.OutputFolderPath)
+		}
+
+		logger.removeExpiredLogFiles(logger.normalizeTimestamp(generateTimestamp()))
+	}
+
+	logger.openGzipOutputs()
+
+	if opt.OutputToSyslog {
+		logger.connectSyslog()
+	}
+
+	if opt.WebhookURL != "" {
+		logger.startWebhook()
+	}
+
+	if opt.ReopenOnSIGHUP {
+		logger.reopenOnSIGHUP()
+	}
 
 	go logger.processLogs()
 
-	logger.Entry(firstEntry)
+	// A zero first entry starts the logger quietly
+	if !reflect.ValueOf(firstEntry).IsZero() {
+		logger.Entry(firstEntry)
+	}
 
 	return logger, nil
 }
@@ -69,15 +344,32 @@ func NewLogger(format []LogFormat, opt Options, firstEntry Container) (*Logger,
 // Logs a message based on the provided container.
 //
 // If the timestamp of the provided container is zero, it will be set to the current
-// timestamp using the generateTimestamp function.
+// timestamp using the generateTimestamp function. If the format contains FORMAT_CALLER, the source location
+// of the caller is captured here, because the entry is formatted asynchronously in another goroutine.
+// For the same reason the request body is recorded here if the format contains FORMAT_HTTP_BODY.
+//
+// The log entry is then sent to the logger's LogChan channel for further processing. If the channel is full,
+// Entry blocks until there is space, or drops the entry if Options.DropWhenFull is set.
+// If Options.Synchronous is set, the entry bypasses LogChan and is processed and written before Entry returns;
+// concurrent Entry calls are serialized, so a slow output blocks every logging goroutine.
 //
-// The log entry is then sent to the logger's LogChan channel for further processing.
+// If Options.ExitOnFatal is set, a STATUS_FATAL entry closes the logger, which waits until the entry
+// (and every entry before it) has been written and flushed, and then exits the process.
 //
 // Parameters:
 //   - c: Container - the log entry container containing the log message and metadata
 func (l *Logger) Entry(c Container) {
+	l.entry(c, 1)
+}
+
+// Sends a log entry to the logger, see Entry.
+//
+// Parameters:
+//   - c: Container - the log entry container
+//   - depth: int - the number of exported wrapper frames (e.g. Entry) between entry and the code which logs, used for FORMAT_CALLER
+func (l *Logger) entry(c Container, depth int) {
 	// Check for element - if empty: logger disabled
-	if len(l.Format) == 0 {
+	if len(l.format()) == 0 {
 		return
 	}
 
@@ -85,7 +377,178 @@ func (l *Logger) Entry(c Container) {
 		c.Timestamp = generateTimestamp()
 	}
 
-	l.LogChan <- c
+	if c.Caller == "" && l.hasFormat(FORMAT_CALLER) {
+		c.Caller = getCaller(1 + depth + l.Options.CallerSkip)
+	}
+
+	// The body must be read before Entry returns, the handler may consume it afterwards
+	if c.HttpBody == "" && l.hasFormat(FORMAT_HTTP_BODY) {
+		c.HttpBody = l.captureHttpBody(c.HttpRequest)
+	}
+
+	l.enqueue(c)
+
+	// Terminate only after the entry was written, Close waits until all queued entries are processed
+	if c.Status == STATUS_FATAL && l.Options.ExitOnFatal {
+		l.Close()
+		exitFunc(l.fatalExitCode())
+	}
+}
+
+// Sends a log entry to the log channel.
+//
+// Parameters:
+//   - c: Container - the log entry container
+func (l *Logger) enqueue(c Container) {
+	// Fast path for entries after Close, no need to wait for a running Close
+	if l.closed.Load() {
+		l.lateEntries.Add(1)
+		return
+	}
+
+	l.closeMu.RLock()
+	defer l.closeMu.RUnlock()
+
+	// Entries after Close are ignored instead of panicking on the closed channel. The flag is checked
+	// again under the lock, because Close may have finished since the check above.
+	if l.closed.Load() {
+		l.lateEntries.Add(1)
+		return
+	}
+
+	// In synchronous mode the entry is written before Entry returns, concurrent callers wait for each other
+	if l.Options.Synchronous {
+		l.processMu.Lock()
+		defer l.processMu.Unlock()
+		l.processEntry(c)
+		l.flushBatch()
+		return
+	}
+
+	if !l.Options.DropWhenFull {
+		l.LogChan <- c
+		return
+	}
+
+	select {
+	case l.LogChan <- c:
+	default:
+		l.dropped.Add(1)
+		// Reported on the calling goroutine, so the ErrorHandler must be safe for concurrent use
+		l.reportError("queue log entry", ErrLogQueueFull)
+	}
+}
+
+// Terminates the process after a FATAL entry if Options.ExitOnFatal is set, replaceable in tests
+var exitFunc = os.Exit
+
+// Returns the exit code used by Options.ExitOnFatal.
+//
+// Returns:
+//   - int: Options.FatalExitCode, or 1 if it is not set
+func (l *Logger) fatalExitCode() int {
+	if l.Options.FatalExitCode == 0 {
+		return 1
+	}
+	return l.Options.FatalExitCode
+}
+
+// Returns the number of entries which were dropped because the log channel was full.
+//
+// Entries are only dropped if Options.DropWhenFull is set.
+//
+// Returns:
+//   - int: the number of dropped entries
+func (l *Logger) DroppedCount() int {
+	return int(l.dropped.Load())
+}
+
+// Returns the number of entries which were ignored because Entry was called after Close.
+//
+// Returns:
+//   - int: the number of ignored entries
+func (l *Logger) LateEntryCount() int {
+	return int(l.lateEntries.Load())
+}
+
+// Blocks until all entries passed to Entry before the call have been processed.
+//
+// A sentinel is enqueued behind the pending entries and Flush returns once processLogs reaches it,
+// so everything ahead of it has been written to the outputs (including buffered log file writes and the gzip streams of Options.GzipOutputs). Entries held back for aggregation
+// (see Options.AggregateWindow) are not written before their window ends. Returns immediately
+// if the logger is closed.
+//
+// Example:
+//
+//	appLogger.Entry(logger.Container{Status: logger.STATUS_INFO, Info: "done"})
+//	appLogger.Flush()
+//	// the entry is written now
+func (l *Logger) Flush() {
+	flushed := make(chan struct{})
+
+	l.closeMu.RLock()
+	if l.closed.Load() {
+		l.closeMu.RUnlock()
+		return
+	}
+	l.LogChan <- Container{flushed: flushed}
+	l.closeMu.RUnlock()
+
+	<-flushed
+}
+
+// Shuts the logger down.
+//
+// It closes the log channel, waits until all pending entries have been processed (including pending
+// aggregates and the optional summary), stops the SIGHUP handler, closes the log file and terminates the gzip streams of Options.GzipOutputs.
+// Calls to Entry after Close are a no-op, they are only counted (see LateEntryCount). Calling Close more than once is safe and returns the same result.
+//
+// Returns:
+//   - error: the last error which occurred while writing to the log file and/or an error if the
+//     log file or the syslog connection could not be closed
+func (l *Logger) Close() error {
+	l.closeOnce.Do(func() {
+		// Wait for running Entry calls, then reject further entries
+		l.closeMu.Lock()
+		l.closed.Store(true)
+		close(l.LogChan)
+		l.closeMu.Unlock()
+
+		<-l.done
+
+		l.stopSIGHUP()
+		l.unsubscribeAll()
+		l.stopWebhook()
+
+		l.fileMu.Lock()
+		l.closeErr = errors.Join(l.writeErr, l.closeLogFile(), l.closeSyslog(), l.closeGzipOutputs())
+		l.fileMu.Unlock()
+	})
+
+	return l.closeErr
+}
+
+// Returns a human readable summary of the logger's lifetime.
+//
+// Returns:
+//   - string: the summary
+//
+// Example:
+//
+//	// Output: Logger closed after 1m2.5s: 15 entries [INFO: 6] [WARN: 1] [ERROR: 8], 0 sampled out, 0 dropped
+func (l *Logger) summary() string {
+	var builder strings.Builder
+
+	total := 0
+	for _, count := range l.StatusCounts() {
+		total += count
+	}
+
+	builder.WriteString(fmt.Sprintf("Logger closed after %s: %d entries", time.Since(l.started).Round(time.Millisecond), total))
+	builder.WriteString(strings.TrimPrefix(l.GetLogStatusCounters(), "Log Level Counters:"))
+	builder.WriteString(fmt.Sprintf(", %d sampled out, %d dropped", l.sampledOut, l.DroppedCount()))
+
+	return builder.String()
 }
 
 // Creates the current timestamp.
@@ -96,97 +559,501 @@ func generateTimestamp() time.Time {
 	return time.Now()
 }
 
-// Formats the given timestamp and returns it as a string.
+// Converts a timestamp to the configured time zone.
+//
+// The log line and the daily log file name are both derived from the converted timestamp,
+// so an entry always lands in the file of the day shown in its line.
+//
+// Parameters:
+//   - timestamp: time.Time - the timestamp to convert
+//
+// Returns:
+//   - time.Time: the timestamp in UTC if Options.UseUTC is set, otherwise unchanged
+func (l *Logger) normalizeTimestamp(timestamp time.Time) time.Time {
+	if l.Options.UseUTC {
+		return timestamp.UTC()
+	}
+	return timestamp
+}
+
+// Formats the given timestamp according to the configured timestamp mode and returns it as a string.
 //
 // Parameters:
 //   - timestamp: time.Time - the timestamp to format
 //
 // Returns:
 //   - string: the formatted timestamp
-func formatTimestamp(timestamp time.Time) string {
-	return timestamp.Format(time.RFC3339)
+func (l *Logger) formatTimestamp(timestamp time.Time) string {
+	if l.Options.TimestampMode == TIMESTAMP_RELATIVE {
+		return formatRelativeTimestamp(time.Since(timestamp))
+	}
+	return timestamp.Format(l.timestampLayout())
+}
+
+// Returns a compact, humanized representation of the age of an entry.
+//
+// The age is truncated to the largest fitting unit (seconds, minutes, hours or days).
+// Ages below one second (or negative ages caused by clock skew) are rendered as "now".
+//
+// Parameters:
+//   - age: time.Duration - the time elapsed since the entry was created
+//
+// Returns:
+//   - string: the humanized age e.g. "2s ago", "5m ago", "3h ago", "1d ago"
+func formatRelativeTimestamp(age time.Duration) string {
+	switch {
+	case age < time.Second:
+		return "now"
+	case age < time.Minute:
+		return fmt.Sprintf("%ds ago", int(age/time.Second))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age/time.Minute))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(age/(24*time.Hour)))
+	}
 }
 
 // Processes logs from the log channel and writes them to the log file.
 //
 // It is a method of the Logger type and is executed as a goroutine. It continuously reads log entries
-// from the log channel (`l.LogChan`) and processes each log entry by formatting it based on the configured
-// log format items. The formatted log message is then written to the log file and also printed to STDOUT.
-//
-// This method uses various helper functions to format different log components based on the configured format items.
-// It also trims any trailing spaces from the formatted log message before writing it to the log file.
+// from the log channel (`l.LogChan`) and hands each log entry to processEntry. Entries which are already
+// queued are drained as one batch (up to maxBatchSize), whose lines are written to the log file with a
+// single write instead of one write per entry. If an aggregation window
+// is configured, a ticker periodically flushes the collected aggregates as summary entries. With rate limits,
+// pending suppression notices are written once per second, and with Options.RepeatWindow pending repeat
+// notices once per window, even if no further entry arrives.
 func (l *Logger) processLogs() {
-	for c := range l.LogChan {
-
-		// Create buffer
-		var result strings.Builder
-
-		for _, formatItem := range l.Format {
-			switch formatItem {
-			case FORMAT_STATUS:
-				if str := logStatustoString[c.Status]; str != "" {
-					// Increment the log level counter
-					incrementLogStatusCounter(l, c.Status)
-					result.WriteString(str + " ")
-				}
-			case FORMAT_PRE_TEXT:
-				if c.PreText != "" {
-					result.WriteString(c.PreText + " ")
-				}
-			case FORMAT_ID:
-				if c.Id != "" {
-					result.WriteString(c.Id + " ")
-				}
-			case FORMAT_SOURCE:
-				if c.Source != "" {
-					result.WriteString(c.Source + " ")
-				}
-			case FORMAT_INFO:
-				if c.Info != "" {
-					result.WriteString(c.Info + " ")
-				}
-			case FORMAT_DATA:
-				if c.Data != "" {
-					result.WriteString(c.Data + " ")
-				}
-			case FORMAT_ERROR:
-				if c.Error != "" {
-					result.WriteString(c.Error + " ")
-				}
-			case FORMAT_PROCESSING_TIME:
-				if str := getProcessingTime(c.ProcessingTime); str != "" {
-					result.WriteString(str + " ")
-				}
-			case FORMAT_TIMESTAMP:
-				if str := formatTimestamp(c.Timestamp); str != "" {
-					result.WriteString(str + " ")
+	var aggregateTick <-chan time.Time
+	if l.aggregationEnabled() {
+		ticker := time.NewTicker(l.Options.AggregateWindow)
+		defer ticker.Stop()
+		aggregateTick = ticker.C
+	}
+
+	var rateLimitTick <-chan time.Time
+	if len(l.Options.RateLimits) > 0 {
+		ticker := time.NewTicker(rateLimitWindow)
+		defer ticker.Stop()
+		rateLimitTick = ticker.C
+	}
+
+	var repeatTick <-chan time.Time
+	if l.Options.RepeatWindow > 0 {
+		ticker := time.NewTicker(l.Options.RepeatWindow)
+		defer ticker.Stop()
+		repeatTick = ticker.C
+	}
+
+	var flushTick <-chan time.Time
+	if l.Options.FlushInterval > 0 {
+		ticker := time.NewTicker(l.Options.FlushInterval)
+		defer ticker.Stop()
+		flushTick = ticker.C
+	}
+
+	for {
+		select {
+		case c, ok := <-l.LogChan:
+			l.processMu.Lock()
+
+			// Process the entries which are ready as one batch, so their log file writes are coalesced
+			l.batching = true
+		batch:
+			for n := 1; ok; n++ {
+				l.processQueued(c)
+				if n == maxBatchSize {
+					break
 				}
-			case FORMAT_HTTP_REQUEST:
-				if str := getHttpRequest(c.HttpRequest); str != "" {
-					result.WriteString(str + " ")
+				select {
+				case c, ok = <-l.LogChan:
+				default:
+					break batch
 				}
-			case FORMAT_PROCESSED_DATA:
-				if str := getProcessedData(c.ProcessedData); str != "" {
-					result.WriteString(str + " ")
+			}
+			l.batching = false
+			l.flushBatch()
+
+			if !ok {
+				l.writeRepeatNotice(generateTimestamp())
+				l.flushAggregates()
+				l.flushSuppressionNotices()
+				if l.Options.SummaryOnClose {
+					l.writeEntry(Container{
+						Status:    STATUS_INFO,
+						Info:      l.summary(),
+						Timestamp: generateTimestamp(),
+					})
 				}
+				l.processMu.Unlock()
+				close(l.done)
+				return
 			}
+			l.processMu.Unlock()
+		case <-aggregateTick:
+			l.processMu.Lock()
+			l.flushAggregates()
+			l.processMu.Unlock()
+		case <-rateLimitTick:
+			l.processMu.Lock()
+			l.flushSuppressionNotices()
+			l.processMu.Unlock()
+		case <-repeatTick:
+			l.processMu.Lock()
+			l.expireRepeatWindow(generateTimestamp())
+			l.processMu.Unlock()
+		case <-flushTick:
+			l.flushLogFile()
+			l.processMu.Lock()
+			l.flushGzipOutputs()
+			l.processMu.Unlock()
 		}
+	}
+}
 
-		trimmedResult := strings.TrimRight(result.String(), " ")
+// Maximum number of entries processed as one batch, bounds the delay until a busy logger flushes the log file
+const maxBatchSize = 256
 
-		if l.Options.OutputToFile {
-			writeLogToFile(l.Options.OutputFolderPath, trimmedResult, &c)
+// Processes an entry received from LogChan, which is either a log entry or the sentinel of Flush.
+//
+// Parameters:
+//   - c: Container - the received container
+func (l *Logger) processQueued(c Container) {
+	if c.flushed != nil {
+		l.flushLogFile()
+		l.flushGzipOutputs()
+		close(c.flushed)
+		return
+	}
+	l.processEntry(c)
+}
+
+// Writes the log file writes buffered during a batch to the log file and flushes the gzip outputs.
+//
+// Does nothing if Options.FlushInterval is set, the buffers are then flushed by the ticker. The caller must hold processMu.
+func (l *Logger) flushBatch() {
+	if l.Options.FlushInterval > 0 {
+		return
+	}
+	l.flushLogFile()
+	l.flushGzipOutputs()
+}
+
+// Formats the log entry and writes it to the configured outputs.
+//
+// Depending on Options.OutputFormat the log entry is formatted as text or as a JSON object.
+// Every function in Options.Hooks is then called with the entry, before the formatted log message is
+// written to the log file, STDOUT, every writer in Options.Outputs and (compressed) in Options.GzipOutputs.
+// A failing destination is reported via Options.ErrorHandler and does not keep the others from being written.
+//
+// Parameters:
+//   - c: Container - the log entry container
+func (l *Logger) writeEntry(c Container) {
+	c.Timestamp = l.normalizeTimestamp(c.Timestamp)
+	// Numbered at emission, so the sequence follows the write order rather than the order of the Entry calls
+	c.seq = l.seq.Add(1)
+	message := l.formatMessage(&c)
+
+	// Hooks run in the logging goroutine, a slow hook delays every further entry
+	for _, hook := range l.Options.Hooks {
+		hook(c)
+	}
+
+	if l.Options.OutputToFile {
+		l.writeLogToFile(l.formatFileMessage(&c, message), &c)
+	}
+	if l.Options.OutputToStdout {
+		stdoutMessage := message
+		if l.colorStdout() && message != "" && l.Options.OutputFormat == OUTPUT_TEXT {
+			stdoutMessage = l.formatText(&c, l.format(), true)
+		}
+		if _, err := io.WriteString(l.stdout(), l.terminateLine(stdoutMessage)); err != nil {
+			l.reportError("write to STDOUT", err)
 		}
-		if l.Options.OutputToStdout {
-			fmt.Println(trimmedResult)
+	}
+	for i, w := range l.Options.Outputs {
+		if _, err := io.WriteString(w, l.terminateLine(message)); err != nil {
+			l.reportError(fmt.Sprintf("write to output %d", i), err)
+		}
+	}
+	for i, zw := range l.gzipOutputs {
+		if _, err := io.WriteString(zw, l.terminateLine(message)); err != nil {
+			l.reportError(fmt.Sprintf("write to gzip output %d", i), err)
 		}
+		l.gzipPending = true
 	}
+	if l.Options.RecentEntries > 0 {
+		l.recent.add(message, l.Options.RecentEntries)
+	}
+	if l.syslog != nil {
+		l.writeToSyslog(&c)
+	}
+	if l.webhookQueue != nil {
+		l.sendToWebhook(&c)
+	}
+
+	l.publish(c)
+}
+
+// Returns the line ending written after every log message.
+//
+// Returns:
+//   - string: Options.LineEnding, or "\n" if it is not set
+func (l *Logger) lineEnding() string {
+	return stringOrDefault(l.Options.LineEnding, "\n")
+}
+
+// Returns the message terminated by the line ending, ready to be written.
+//
+// With a custom Options.LineEnding the line breaks inside the message (e.g. of stack traces, HTTP bodies or
+// multi-line info texts) are converted as well, so the output uses one line ending throughout.
+//
+// Parameters:
+//   - message: string - the formatted log message
+//
+// Returns:
+//   - string: the message with converted line breaks and the line ending appended
+func (l *Logger) terminateLine(message string) string {
+	ending := l.lineEnding()
+	if ending != "\n" && strings.Contains(message, "\n") {
+		message = strings.ReplaceAll(strings.ReplaceAll(message, "\r\n", "\n"), "\n", ending)
+	}
+	return message + ending
+}
+
+// Formats a log entry exactly like it is written to the outputs, without writing it anywhere.
+//
+// The entry is formatted according to the format and Options.OutputFormat of the logger, e.g. to render
+// a single entry in an admin UI. Note that the processing pipeline (sampling, redaction of Container.Data,
+// custom stages) is not applied. The method is named FormatEntry because Format is the format field.
+//
+// Parameters:
+//   - c: Container - the log entry container
+//
+// Returns:
+//   - string: the formatted line (or multi-line message for the processed data) without a trailing newline
+//
+// Example:
+//
+//	line := appLogger.FormatEntry(logger.Container{Status: logger.STATUS_INFO, Info: "user created"})
+//	// line will be e.g. "INFO user created"
+func (l *Logger) FormatEntry(c Container) string {
+	c.Timestamp = l.normalizeTimestamp(c.Timestamp)
+	return l.formatMessage(&c)
+}
+
+// Formats the log entry according to the format and Options.OutputFormat of the logger.
+//
+// Parameters:
+//   - c: *Container - the log entry container
+//
+// Returns:
+//   - string: the formatted message
+func (l *Logger) formatMessage(c *Container) string {
+	return l.formatMessageAs(c, l.format(), l.Options.OutputFormat)
+}
+
+// Formats the log entry with the given format items and output format.
+//
+// Parameters:
+//   - c: *Container - the log entry container
+//   - format: []LogFormat - the format items
+//   - output: OutputFormat - how the entry is serialized
+//
+// Returns:
+//   - string: the formatted message
+func (l *Logger) formatMessageAs(c *Container, format []LogFormat, output OutputFormat) string {
+	switch output {
+	case OUTPUT_JSON, OUTPUT_NDJSON:
+		return l.formatJSON(c, format, output)
+	default:
+		return l.formatText(c, format, false)
+	}
+}
+
+// Formats the log entry for the log file.
+//
+// Parameters:
+//   - c: *Container - the log entry container
+//   - message: string - the entry formatted by formatMessage
+//
+// Returns:
+//   - string: the message, or the entry formatted with Options.FileFormat and Options.FileOutputFormat if a file format is set
+func (l *Logger) formatFileMessage(c *Container, message string) string {
+	if l.Options.FileFormat == nil {
+		return message
+	}
+	return l.formatMessageAs(c, l.Options.FileFormat, l.Options.FileOutputFormat)
+}
+
+// Returns the destination of the STDOUT output.
+//
+// Returns:
+//   - io.Writer: Options.Stdout, or os.Stdout if it is not set
+func (l *Logger) stdout() io.Writer {
+	if l.Options.Stdout == nil {
+		return os.Stdout
+	}
+	return l.Options.Stdout
+}
+
+// Formats the log entry as a line of text.
+//
+// The log entry is formatted based on the configured log format items. Various helper functions
+// are used to format the different log components. The fields are separated by Options.FieldSeparator
+// (default a single space), which is only written between fields, so the message never ends with a
+// separator. Any trailing spaces are trimmed from the formatted log message.
+//
+// Parameters:
+//   - c: *Container - the log entry container
+//   - format: []LogFormat - the format items e.g. Logger.Format
+//   - colored: bool - wraps the status in ANSI colors (only used for STDOUT)
+//
+// Returns:
+//   - string: the formatted log message
+func (l *Logger) formatText(c *Container, format []LogFormat, colored bool) string {
+	// Create buffer
+	var result strings.Builder
+	separator := stringOrDefault(l.Options.FieldSeparator, " ")
+
+	for _, formatItem := range format {
+		str := l.formatTextField(formatItem, c)
+		if str == "" {
+			// Empty fields are skipped unless a placeholder keeps the columns aligned
+			str = l.Options.EmptyFieldPlaceholder
+		}
+		if str != "" {
+			if l.Options.EscapeNewlines {
+				str = newlineEscaper.Replace(str)
+			}
+			if colored && formatItem == FORMAT_STATUS {
+				str = colorizeStatus(c.Status, str)
+			}
+			if result.Len() > 0 {
+				result.WriteString(separator)
+			}
+			result.WriteString(str)
+		}
+	}
+
+	// Prefix and suffix are added after trimming, so a suffix ending with a space is kept
+	message := l.prefixContinuationLines(strings.TrimRight(result.String(), " "), c)
+	return l.Options.LinePrefix + message + l.Options.LineSuffix
+}
+
+// Replaces line breaks with their escape sequences, see Options.EscapeNewlines
+var newlineEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`)
+
+// Formats a single field of the log entry as text.
+//
+// Parameters:
+//   - formatItem: LogFormat - the field to format
+//   - c: *Container - the log entry container
+//
+// Returns:
+//   - string: the formatted field, or an empty string if the field is empty
+func (l *Logger) formatTextField(formatItem LogFormat, c *Container) string {
+	switch formatItem {
+	case FORMAT_STATUS:
+		return logStatustoString[c.Status]
+	case FORMAT_PRE_TEXT:
+		return c.PreText
+	case FORMAT_ID:
+		return c.Id
+	case FORMAT_SOURCE:
+		return c.Source
+	case FORMAT_INFO:
+		return c.Info
+	case FORMAT_DATA:
+		return c.Data
+	case FORMAT_ERROR:
+		return strings.TrimSpace(l.getError(c) + " " + getErrors(c.Errors))
+	case FORMAT_PROCESSING_TIME:
+		return getProcessingTime(c.ProcessingTime, l.Options.ProcessingTimeUnit)
+	case FORMAT_TIMESTAMP:
+		return l.formatTimestamp(c.Timestamp)
+	case FORMAT_HTTP_REQUEST:
+		return l.getHttpRequest(c.HttpRequest)
+	case FORMAT_PROCESSED_DATA:
+		data, err := getProcessedData(l.normalizeData(c.ProcessedData), l.Options.CompactProcessedData)
+		if err != nil {
+			l.reportError("serialize processed data", err)
+		}
+		return data
+	case FORMAT_GOROUTINES:
+		return getGoroutines()
+	case FORMAT_MEMSTATS:
+		return l.getMemStats()
+	case FORMAT_FIELDS:
+		return getFields(l.textFieldValues(l.redactFields(c.Fields)))
+	case FORMAT_CALLER:
+		return c.Caller
+	case FORMAT_HTTP_BODY:
+		return c.HttpBody
+	case FORMAT_HTTP_DETAILED:
+		return getHttpRequestDetails(l.getHttpRequestDetails(c.HttpRequest))
+	case FORMAT_HTTP_STATUS:
+		if c.HttpStatus != 0 {
+			return strconv.Itoa(c.HttpStatus)
+		}
+	case FORMAT_RESPONSE_BYTES:
+		if c.ResponseBytes != 0 {
+			return strconv.FormatInt(c.ResponseBytes, 10) + " bytes"
+		}
+	case FORMAT_HOSTNAME:
+		return l.hostname
+	case FORMAT_PID:
+		return strconv.Itoa(os.Getpid())
+	case FORMAT_SEQ:
+		if c.seq != 0 {
+			return fmt.Sprintf("%010d", c.seq)
+		}
+	}
+	if custom, ok := lookupCustomFormat(formatItem); ok {
+		return custom.render(*c)
+	}
+	return ""
+}
+
+// Prefixes every continuation line of a multi-line message according to the configured continuation mode.
+//
+// Parameters:
+//   - message: string - the formatted log message
+//   - c: *Container - the log entry container
+//
+// Returns:
+//   - string: the message with prefixed continuation lines
+//
+// Example:
+//
+//	// CONTINUATION_ID output:
+//	// INFO 5f322ac4ba >Processed Data:
+//	// 5f322ac4ba | {
+//	// 5f322ac4ba |   "age": 30
+//	// 5f322ac4ba | }
+func (l *Logger) prefixContinuationLines(message string, c *Container) string {
+	var prefix string
+	switch l.Options.ContinuationMode {
+	case CONTINUATION_MARKER:
+		prefix = "| "
+	case CONTINUATION_ID:
+		prefix = "| "
+		if c.Id != "" {
+			prefix = c.Id + " | "
+		}
+	default:
+		return message
+	}
+
+	return strings.ReplaceAll(message, "\n", "\n"+prefix)
 }
 
 // Returns a formatted string representation of an HTTP request.
 //
-// It takes an *http.Request object as input and returns a string containing the remote address,
-// HTTP method, and URL of the request. If the provided HTTP request is nil, an empty string is returned.
+// It takes an *http.Request object as input and returns a string containing the client address
+// (see clientAddr), HTTP method, and URL of the request. If the provided HTTP request is nil, an empty
+// string is returned.
 //
 // Parameters:
 //   - httpRequest: *http.Request - the HTTP request object to format
@@ -200,135 +1067,374 @@ func (l *Logger) processLogs() {
 //	    Method: "GET",
 //	    URL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/"},
 //	}
-//	result := getHttpRequest(request)
+//	result := l.getHttpRequest(request)
 //	// result will be "192.168.0.1:12345 GET https://example.com/"
-func getHttpRequest(httpRequest *http.Request) string {
+func (l *Logger) getHttpRequest(httpRequest *http.Request) string {
 	if httpRequest != nil {
-		return (httpRequest.RemoteAddr + " " + httpRequest.Method + " " + httpRequest.URL.String())
+		return (l.clientAddr(httpRequest) + " " + httpRequest.Method + " " + httpRequest.URL.String())
 	}
 	return ""
 }
 
+// Returns the error message of the log entry.
+//
+// If only one of Container.Error and Container.Err is set, it is returned. If both are set,
+// Options.ErrorPrecedence decides: ERROR_PREFER_ERR (default) returns the message of Err,
+// ERROR_CONCAT returns both as "<Error>: <Err>". With Options.ExpandErrorChain the message of Err is
+// replaced by its layers joined with ": ", see errorChain.
+//
+// Parameters:
+//   - c: *Container - the log entry container
+//
+// Returns:
+//   - string: the error message, or an empty string if no error is set
+func (l *Logger) getError(c *Container) string {
+	if c.Err == nil {
+		return c.Error
+	}
+
+	message := c.Err.Error()
+	if l.Options.ExpandErrorChain {
+		message = strings.Join(errorChain(c.Err), ": ")
+	}
+
+	if c.Error != "" && l.Options.ErrorPrecedence == ERROR_CONCAT {
+		return c.Error + ": " + message
+	}
+	return message
+}
+
+// Returns a numbered list of the provided errors.
+//
+// Each non-nil error is rendered as "[N] message" where N starts at 1. Nil errors are skipped
+// without consuming a number. Errors combining several errors (e.g. from errors.Join) are split
+// into one item per contained error. If no error remains, an empty string is returned.
+//
+// Parameters:
+//   - errs: []error - the errors to format
+//
+// Returns:
+//   - string: the formatted error list
+//
+// Example:
+//
+//	result := getErrors([]error{errors.New("disk full"), errors.New("timeout")})
+//	// result will be "[1] disk full [2] timeout"
+func getErrors(errs []error) string {
+	var builder strings.Builder
+
+	for i, err := range flattenErrors(errs) {
+		if i > 0 {
+			builder.WriteString(" ")
+		}
+		builder.WriteString(fmt.Sprintf("[%d] %s", i+1, err.Error()))
+	}
+
+	return builder.String()
+}
+
+// Returns the non-nil errors with combined errors split up.
+//
+// Errors implementing Unwrap() []error (e.g. created by errors.Join) are replaced by the errors they
+// contain, recursively, so every single error can be listed on its own.
+//
+// Parameters:
+//   - errs: []error - the errors to flatten
+//
+// Returns:
+//   - []error: the contained non-nil errors in order
+func flattenErrors(errs []error) []error {
+	var flat []error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			flat = append(flat, flattenErrors(joined.Unwrap())...)
+			continue
+		}
+		flat = append(flat, err)
+	}
+	return flat
+}
+
+// Formats structured fields as key=value pairs.
+//
+// The keys are sorted, so the output is deterministic. Values containing spaces, quotes or "=" are quoted.
+//
+// Parameters:
+//   - fields: map[string]any - the fields to format
+//
+// Returns:
+//   - string: the formatted fields, or an empty string if there are none
+//
+// Example:
+//
+//	result := getFields(map[string]any{"user_id": 42, "action": "sign in"})
+//	// result will be "action=\"sign in\" user_id=42"
+func getFields(fields map[string]any) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := fmt.Sprint(fields[key])
+		if value == "" || strings.ContainsAny(value, " \t\"=") {
+			value = strconv.Quote(value)
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// Normalizes the values of structured fields for the text output, so they match the JSON output.
+//
+// Every value is normalized like in the JSON output (see normalizeData), e.g. durations become "1.50 ms"
+// and times use the timestamp layout. Maps, slices and structs are rendered as compact JSON.
+//
+// Parameters:
+//   - fields: map[string]any - the fields to normalize
+//
+// Returns:
+//   - map[string]any: the fields with normalized values, ready for getFields
+func (l *Logger) textFieldValues(fields map[string]any) map[string]any {
+	result := make(map[string]any, len(fields))
+	for key, value := range fields {
+		switch normalized := l.normalizeData(value).(type) {
+		case orderedObject, map[string]any, []any:
+			encoded, err := encodeJSON(normalized)
+			if err != nil {
+				result[key] = unserializablePlaceholder(err)
+				continue
+			}
+			result[key] = string(encoded)
+		default:
+			result[key] = normalized
+		}
+	}
+	return result
+}
+
+// Returns the source location of a function on the call stack.
+//
+// Parameters:
+//   - skip: int - the number of stack frames to skip, 0 identifies the caller of getCaller
+//
+// Returns:
+//   - string: the file name and line e.g. "main.go:42", or an empty string if it cannot be determined
+func getCaller(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+}
+
+// Returns the number of active goroutines as a formatted string.
+//
+// Returns:
+//   - string: the number of goroutines e.g. "goroutines=42"
+func getGoroutines() string {
+	return fmt.Sprintf("goroutines=%d", runtime.NumGoroutine())
+}
+
+// Default caching interval of the FORMAT_MEMSTATS values
+const defaultMemStatsInterval = 10 * time.Second
+
+// Returns a compact view of the memory statistics as a formatted string.
+//
+// Since runtime.ReadMemStats stops the world, the statistics are only read once per
+// Options.MemStatsInterval (default 10s) and cached in between. This keeps the cost
+// negligible even under high log rates. The cache is shared by processLogs and FormatEntry and
+// guarded by a mutex.
+//
+// Returns:
+//   - string: the memory statistics e.g. "alloc=12MB sys=40MB"
+func (l *Logger) getMemStats() string {
+	interval := l.Options.MemStatsInterval
+	if interval <= 0 {
+		interval = defaultMemStatsInterval
+	}
+
+	l.memStatsMu.Lock()
+	defer l.memStatsMu.Unlock()
+
+	if now := time.Now(); l.memStats == "" || now.Sub(l.memStatsRead) >= interval {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+
+		l.memStats = fmt.Sprintf("alloc=%dMB sys=%dMB", m.Alloc/1024/1024, m.Sys/1024/1024)
+		l.memStatsRead = now
+	}
+
+	return l.memStats
+}
+
 // Returns the processing time as a formatted string.
 //
 // It takes a time.Duration value representing the processing time as input. The function
-// converts the processing time to milliseconds and formats it as "[X ms]", where X is the
-// number of milliseconds. If the processing time is less than 0.01 ms, "0.01 ms" is returned.
+// converts the processing time to the given unit and formats it as "[X ms]", where X is the
+// number of milliseconds (two decimal places). Nanoseconds are rendered as integer, microseconds
+// and seconds with two decimal places. Durations below the precision are rendered as zero e.g. "[0.00 ms]".
+// A processing time of exactly zero means it was not measured, so an empty string is returned and the field is skipped.
 //
 // Parameters:
 //   - processingTime: time.Duration - the processing time to format
+//   - unit: ProcessingTimeUnit - the unit to render
 //
 // Returns:
-//   - string: the formatted processing time
-func getProcessingTime(processingTime time.Duration) string {
-	// Convert the processingTime to milliseconds
-	processingTimeMs := float64(processingTime.Microseconds()) / 1000.0
-
-	// Check if the processing time is less than 0.01 ms
-	if processingTimeMs < 0.01 {
-		processingTimeMs = 0.01
+//   - string: the formatted processing time, or an empty string if it is zero
+func getProcessingTime(processingTime time.Duration, unit ProcessingTimeUnit) string {
+	if processingTime == 0 {
+		return ""
 	}
 
-	// Format the time as a string with two decimal places
-	formattedTime := fmt.Sprintf("%.2f ms", processingTimeMs)
-
 	// Enclose the formatted time in square brackets
-	result := "[" + formattedTime + "]"
+	result := "[" + formatDuration(processingTime, unit) + "]"
 
 	return result
 }
 
+// Formats a duration in the given unit, like the processing time but without brackets.
+//
+// Durations in Container.ProcessedData and Container.Fields are rendered with this function, so they match
+// FORMAT_PROCESSING_TIME. Unlike the processing time, a zero duration is rendered e.g. as "0.00 ms".
+//
+// Parameters:
+//   - d: time.Duration - the duration to format
+//   - unit: ProcessingTimeUnit - the unit to render
+//
+// Returns:
+//   - string: the formatted duration e.g. "1.50 ms"
+func formatDuration(d time.Duration, unit ProcessingTimeUnit) string {
+	switch unit {
+	case TIME_UNIT_NS:
+		return fmt.Sprintf("%d ns", d.Nanoseconds())
+	case TIME_UNIT_US:
+		return fmt.Sprintf("%.2f µs", float64(d)/float64(time.Microsecond))
+	case TIME_UNIT_S:
+		return fmt.Sprintf("%.2f s", d.Seconds())
+	default:
+		return fmt.Sprintf("%.2f ms", float64(d)/float64(time.Millisecond))
+	}
+}
+
 // Serializes the provided data to JSON format.
 //
 // It takes any value as the input data and marshals it into JSON format using
-// the json.MarshalIndent function. The data is indented with two spaces per level.
-// If an error occurs during the marshaling process, the error message is returned.
+// the json.MarshalIndent function. The data is indented with two spaces per level
+// and prefixed with a ">Processed Data:" line. In compact mode json.Marshal is used
+// and the banner is omitted, so the data stays on a single line.
+// If an error occurs during the marshaling process (e.g. the data contains a channel), a placeholder
+// like "<unserializable: json: unsupported type: chan int>" is returned together with the error.
 // Otherwise, the marshaled data is returned as a string.
 //
 // Parameters:
 //   - processedData: any - the data to be processed
+//   - compact: bool - set true for single-line JSON without the banner (see Options.CompactProcessedData)
 //
 // Returns:
-//   - string: the processed data in JSON format, or an error message
+//   - string: the processed data in JSON format, or the placeholder
+//   - error: the marshaling error, if any
 //
 // Example:
 //
-//	result := getProcessedData(data)
+//	result, _ := getProcessedData(data, true)
 //
 // Output:
 //
-//	{"name": "John Doe", "age": 30}
-func getProcessedData(processedData any) string {
+//	{"age":30,"name":"John Doe"}
+func getProcessedData(processedData any, compact bool) (string, error) {
+	if compact {
+		wJsonBytes, err := json.Marshal(processedData)
+		if err != nil {
+			return unserializablePlaceholder(err), err
+		}
+		return string(wJsonBytes), nil
+	}
+
 	wJsonBytes, err := json.MarshalIndent(processedData, "", "  ")
 	if err != nil {
-		return (err.Error())
+		return unserializablePlaceholder(err), err
 	}
 
 	wJsonData := ">Processed Data:\n" + string(wJsonBytes)
 
-	return wJsonData
+	return wJsonData, nil
 }
 
-// Writes the log message to a log file and also prints it to STDOUT.
-//
-// It formats the log file name as "YYYY_MM_DD.log" based on the log event timestamp.
-// The log file is opened in append mode and created if it doesn't exist.
-// The log message is written to the file
+// Returns the marker which is logged instead of data that cannot be serialized.
 //
 // Parameters:
-//   - folderPath: string - the path of the folder where log files will be stored
-//   - message: string - the log message to write
-//   - c: *Container - the log entry container
-func writeLogToFile(folderPath string, message string, c *Container) {
-	// Format the log file name as YYYY_MM_DD.log based on the log event timestamp
-	// This means that for each day a new log file will be created
-	logFileName := folderPath + c.Timestamp.Format("2006_01_02") + ".log"
+//   - err: error - the serialization error
+//
+// Returns:
+//   - string: the placeholder e.g. "<unserializable: json: unsupported type: chan int>"
+func unserializablePlaceholder(err error) string {
+	return fmt.Sprintf("<unserializable: %v>", err)
+}
 
-	// Open the log file in append mode, create if it doesn't exist
-	file, err := os.OpenFile(logFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		fmt.Println("Failed to open log file:", err)
-		return
+// Returns the permissions of folders created by Options.CreateFolderIfMissing.
+//
+// Returns:
+//   - os.FileMode: the configured mode, or 0755 if none is set
+func (l *Logger) folderMode() os.FileMode {
+	if l.Options.FolderMode == 0 {
+		return 0755
 	}
-	defer file.Close()
+	return l.Options.FolderMode
+}
 
-	// Write the log message to the file
-	_, err = fmt.Fprintln(file, message)
-	if err != nil {
-		fmt.Println("Failed to write to log file:", err)
+// Returns the permissions of log files created by the logger.
+//
+// The process umask is applied on top, e.g. 0664 results in 0644 with the common umask 022.
+//
+// Returns:
+//   - os.FileMode: the configured mode, or 0644 if none is set
+func (l *Logger) fileMode() os.FileMode {
+	if l.Options.FileMode == 0 {
+		return 0644
 	}
+	return l.Options.FileMode
 }
 
 // Checks if the application has write permission to a specific folder.
 //
-// It generates a temporary file path in the provided folder and attempts to create the file.
-// If the application can create the file, it indicates the folder is writable.
-// After creating the file, it is immediately removed.
+// It attempts to create a temporary file with a unique name (e.g. "testfile-123456.tmp") in the provided folder,
+// so loggers initializing concurrently in the same folder do not collide. If the application can create the file,
+// it indicates the folder is writable. After creating the file, it is immediately closed and removed, even if closing fails.
 // If the file creation fails due to a permission error, it implies the folder exists but is not writable.
 // Any other error while creating the file is returned as it is.
 //
 // Parameters:
+//   - fsys: fileSystem - the file system on which the folder resides
 //   - folderPath: string - the path of the folder where write permission is to be checked
 //
 // Returns:
 //   - bool: A boolean indicating whether the application can write to the folder. 'True' indicates writable, 'False' otherwise.
 //   - error: An 'error' that will be non-nil in case of an exception while creating the file.
-func checkWritePermission(folderPath string) (bool, error) {
-	// Generate a test file path
-	testFilePath := folderPath + "testfile.tmp"
+func checkWritePermission(fsys fileSystem, folderPath string) (bool, error) {
+	// An empty path means the working directory (not the temp directory of the system)
+	if folderPath == "" {
+		folderPath = "."
+	}
 
 	// Attempt to create the test file
-	file, err := os.OpenFile(testFilePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	file, testFilePath, err := fsys.CreateTemp(folderPath, "testfile-*.tmp")
 	if err != nil {
 		if os.IsPermission(err) {
 			return false, nil // False with no error means the folder exists but we can't write to it
 		}
 		return false, err // An error other than a permissions error occurred
 	}
-	file.Close() // Close the file if it was created
 
 	// Delete the test file
-	os.Remove(testFilePath)
+	defer fsys.Remove(testFilePath)
+	file.Close()
 
 	return true, nil
 }
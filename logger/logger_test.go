@@ -1,12 +1,18 @@
 package logger
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -31,6 +37,9 @@ func candidateOne(t *testing.T) {
 	// Create a reference timestamp
 	ts := time.Now()
 
+	// Capture the STDOUT output in a buffer
+	var capturedOutput bytes.Buffer
+
 	// Create a new logger with desired format
 	logger, err := NewLogger(
 		[]LogFormat{
@@ -47,8 +56,9 @@ func candidateOne(t *testing.T) {
 			FORMAT_PROCESSED_DATA,
 		}, Options{
 			OutputToStdout:   true,
+			Stdout:           &capturedOutput,
 			OutputToFile:     true,
-			OutputFolderPath: "",
+			OutputFolderPath: t.TempDir(),
 		}, Container{
 			Status:    STATUS_INFO,
 			Info:      "System Logger succesfully started! Awaiting logger tasks...",
@@ -85,33 +95,17 @@ func candidateOne(t *testing.T) {
 		ProcessedData:  data,
 	}
 
-	// Redirect STDOUT to capture the output
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
 	// Call the Entry method to log the container
 	logger.Entry(container)
 
-	duration := 200 * time.Millisecond
-	time.Sleep(duration)
-
-	// Reset STDOUT
-	w.Close()
-	os.Stdout = oldStdout
-
-	// Read the captured output from the pipe
-	var capturedOutput strings.Builder
-	io.Copy(&capturedOutput, r)
+	// Close the logger to wait until all entries are written
+	logger.Close()
 
 	// Verify the captured output
-	res1 := ts.Format(time.RFC3339) + " INFO System Logger succesfully started! Awaiting logger tasks... [0.01 ms] >Processed Data:\nnull\n"
+	res1 := ts.Format(time.RFC3339) + " INFO System Logger succesfully started! Awaiting logger tasks... >Processed Data:\nnull\n"
 	res2 := ts.Format(time.RFC3339) + " INFO SERVER1 192.168.0.1:12345 GET https://example.com 5f322ac4ba handler/user This is an information message 233 something went wrong [1.00 ms]"
 	res3 := " >Processed Data:\n{\n  \"age\": 30,\n  \"isActive\": true,\n  \"name\": \"John Doe\",\n  \"tags\": [\n    \"go\",\n    \"programming\",\n    \"dummy\"\n  ]\n}\n"
 	expected := res1 + res2 + res3
-
-	duration = 200 * time.Millisecond
-	time.Sleep(duration)
 	actual := capturedOutput.String()
 
 	if string(actual) != string(expected) {
@@ -123,6 +117,9 @@ func candidateTwo(t *testing.T) {
 	// Create a reference timestamp
 	ts := time.Now()
 
+	// Capture the STDOUT output in a buffer
+	var capturedOutput bytes.Buffer
+
 	// Create a new logger with desired format
 	logger, err := NewLogger(
 		[]LogFormat{
@@ -138,8 +135,9 @@ func candidateTwo(t *testing.T) {
 			FORMAT_PROCESSED_DATA,
 		}, Options{
 			OutputToStdout:   true,
+			Stdout:           &capturedOutput,
 			OutputToFile:     true,
-			OutputFolderPath: "",
+			OutputFolderPath: t.TempDir(),
 		}, Container{
 			Status:    STATUS_INFO,
 			Info:      "System Logger succesfully started! Awaiting logger tasks...",
@@ -161,32 +159,14 @@ func candidateTwo(t *testing.T) {
 		ProcessingTime: 1 * time.Millisecond,
 	}
 
-	// Redirect STDOUT to capture the output
-	oldStdout := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
 	// Call the Entry method to log the container
 	logger.Entry(container)
 
-	duration := 200 * time.Millisecond
-	time.Sleep(duration)
-
-	// Reset STDOUT
-	w.Close()
-	os.Stdout = oldStdout
-
-	time.Sleep(duration)
-
-	// Read the captured output from the pipe
-	var capturedOutput strings.Builder
-
-	time.Sleep(duration)
-
-	io.Copy(&capturedOutput, r)
+	// Close the logger to wait until all entries are written
+	logger.Close()
 
 	// Verify the captured output
-	expected := "INFO System Logger succesfully started! Awaiting logger tasks... [0.01 ms] >Processed Data:\nnull\nINFO SERVER5 5f322ac4bf handler/user This is an information message 233 something went wrong [1.00 ms] >Processed Data:\nnull\n"
+	expected := "INFO System Logger succesfully started! Awaiting logger tasks... >Processed Data:\nnull\nINFO SERVER5 5f322ac4bf handler/user This is an information message 233 something went wrong [1.00 ms] >Processed Data:\nnull\n"
 	actual := capturedOutput.String()
 
 	if string(actual) != string(expected) {
@@ -202,7 +182,7 @@ func candidateThree(t *testing.T) {
 	logger, err := NewLogger([]LogFormat{}, Options{
 		OutputToStdout:   true,
 		OutputToFile:     true,
-		OutputFolderPath: "",
+		OutputFolderPath: t.TempDir(),
 	}, Container{
 		Status:    STATUS_INFO,
 		Info:      "System Logger succesfully started! Awaiting logger tasks...",
@@ -232,8 +212,8 @@ func candidateThree(t *testing.T) {
 	// Call the Entry method to log the container
 	logger.Entry(container)
 
-	duration := 20 * time.Millisecond
-	time.Sleep(duration)
+	// Wait until the entry is processed
+	logger.Flush()
 
 	// Reset STDOUT
 	w.Close()
@@ -264,7 +244,7 @@ func candidateFour(t *testing.T) {
 		}, Options{
 			OutputToStdout:   true,
 			OutputToFile:     true,
-			OutputFolderPath: "",
+			OutputFolderPath: t.TempDir(),
 		}, Container{
 			Status:    STATUS_INFO,
 			Info:      "System Logger succesfully started! Awaiting logger tasks...",
@@ -322,11 +302,11 @@ func candidateFour(t *testing.T) {
 	logger.Entry(containerTrace)
 	logger.Entry(containerWarn)
 
-	duration := 100 * time.Millisecond
-	time.Sleep(duration)
+	// Wait until all entries are processed
+	logger.Flush()
 
 	// Verify the captured output
-	expected := "Log Level Counters: [INFO: 6] [WARN: 1] [TRACE: 2] [ERROR: 4] [FATAL: 3]"
+	expected := "Log Level Counters: [TRACE: 2] [INFO: 6] [WARN: 1] [ERROR: 4] [FATAL: 3]"
 	actual := logger.GetLogStatusCounters()
 
 	if string(actual) != string(expected) {
@@ -356,12 +336,9 @@ func candidateFive(t *testing.T) {
 		t.Errorf("Unexpected result: Code should throw an error here")
 	}
 
-	// Verify the captured output
-	expected := "open folder/not/existing/testfile.tmp: no such file or directory"
-	actual := err.Error()
-
-	if string(actual) != string(expected) {
-		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	// Verify the returned error
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", fs.ErrNotExist, err)
 	}
 }
 
@@ -385,3 +362,1130 @@ func deleteLogFiles() error {
 
 	return nil
 }
+
+func TestTimestampLayout(t *testing.T) {
+	timestamp := time.Date(2023, 6, 1, 12, 0, 0, 123000000, time.UTC)
+
+	cases := map[string]string{
+		"":                        "2023-06-01T12:00:00Z",
+		"2006-01-02 15:04:05.000": "2023-06-01 12:00:00.123",
+	}
+
+	for layout, expected := range cases {
+		l := &Logger{Options: Options{TimestampLayout: layout}}
+		if actual := l.formatTimestamp(timestamp); actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+	}
+}
+
+func TestFormatRelativeTimestamp(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                      "now",
+		-5 * time.Second:       "now",
+		2 * time.Second:        "2s ago",
+		90 * time.Second:       "1m ago",
+		3 * time.Hour:          "3h ago",
+		49 * time.Hour:         "2d ago",
+		999 * time.Millisecond: "now",
+	}
+
+	for age, expected := range cases {
+		actual := formatRelativeTimestamp(age)
+		if actual != expected {
+			t.Errorf("Unexpected result for %v.\nExpected:\n%#v\nGot:\n%#v", age, expected, actual)
+		}
+	}
+}
+
+func TestFormatCaller(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_CALLER, FORMAT_INFO}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+	}, Container{Status: STATUS_INFO, Info: "started", Caller: "main.go:1"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.Entry(Container{Status: STATUS_INFO, Info: "direct"})
+
+	// A wrapper function is skipped with CallerSkip
+	logger.Options.CallerSkip = 1
+	wrapper := func(info string) {
+		logger.Entry(Container{Status: STATUS_INFO, Info: info})
+	}
+	wrapper("wrapped")
+	logger.Close()
+
+	expected := fmt.Sprintf("main.go:1 started\nlogger_test.go:%d direct\nlogger_test.go:%d wrapped\n", line+1, line+8)
+	if actual := capturedOutput.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestColorStdout(t *testing.T) {
+	defer func(original func(io.Writer) bool) { isTerminal = original }(isTerminal)
+	isTerminal = func(io.Writer) bool { return true }
+	t.Setenv("NO_COLOR", "")
+
+	fsys := newMemFileSystem()
+	var capturedOutput bytes.Buffer
+
+	l := &Logger{
+		Format: []LogFormat{FORMAT_STATUS, FORMAT_INFO},
+		Options: Options{
+			OutputToStdout: true,
+			Stdout:         &capturedOutput,
+			ColorStdout:    true,
+			OutputToFile:   true,
+		},
+		fs: fsys,
+	}
+
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	l.writeEntry(Container{Status: STATUS_INFO, Info: "started", Timestamp: ts})
+	l.writeEntry(Container{Status: STATUS_WARN, Info: "slow", Timestamp: ts})
+	l.writeEntry(Container{Status: STATUS_ERROR, Info: "failed", Timestamp: ts})
+
+	expected := "INFO started\n\033[33mWARN\033[0m slow\n\033[31mERROR\033[0m failed\n"
+	if actual := capturedOutput.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// The log file stays free of escape sequences
+	expected = "INFO started\nWARN slow\nERROR failed\n"
+	if actual := fsys.content("2023_06_01.log"); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestColorStdoutDisabled(t *testing.T) {
+	defer func(original func(io.Writer) bool) { isTerminal = original }(isTerminal)
+
+	cases := []struct {
+		name     string
+		terminal bool
+		noColor  string
+	}{
+		{name: "not a terminal", terminal: false},
+		{name: "NO_COLOR", terminal: true, noColor: "1"},
+	}
+
+	for _, tc := range cases {
+		isTerminal = func(io.Writer) bool { return tc.terminal }
+		t.Setenv("NO_COLOR", tc.noColor)
+
+		var capturedOutput bytes.Buffer
+		l := &Logger{
+			Format:  []LogFormat{FORMAT_STATUS, FORMAT_INFO},
+			Options: Options{OutputToStdout: true, Stdout: &capturedOutput, ColorStdout: true},
+		}
+		l.writeEntry(Container{Status: STATUS_ERROR, Info: "failed"})
+
+		expected := "ERROR failed\n"
+		if actual := capturedOutput.String(); actual != expected {
+			t.Errorf("Unexpected result (%s).\nExpected:\n%#v\nGot:\n%#v", tc.name, expected, actual)
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		OutputToStdout:    true,
+		Stdout:            &capturedOutput,
+		ChannelBufferSize: 100,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	for i := 0; i < 50; i++ {
+		logger.Entry(Container{Status: STATUS_INFO, Info: fmt.Sprint(i)})
+	}
+	logger.Flush()
+
+	if lines := strings.Count(capturedOutput.String(), "\n"); lines != 51 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 51, lines)
+	}
+
+	// Flush after Close returns immediately
+	logger.Close()
+	logger.Flush()
+}
+
+func TestQuietStartup(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	// The zero first entry is not logged
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	logger.Entry(Container{Status: STATUS_WARN, Info: "first"})
+	logger.Close()
+
+	expected := "WARN first\n"
+	if actual := capturedOutput.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestGetProcessingTime(t *testing.T) {
+	cases := []struct {
+		duration time.Duration
+		unit     ProcessingTimeUnit
+		expected string
+	}{
+		{0, TIME_UNIT_MS, ""},
+		{0, TIME_UNIT_NS, ""},
+		{time.Microsecond, TIME_UNIT_MS, "[0.00 ms]"},
+		{1500 * time.Microsecond, TIME_UNIT_MS, "[1.50 ms]"},
+		{1500 * time.Microsecond, TIME_UNIT_NS, "[1500000 ns]"},
+		{1500 * time.Nanosecond, TIME_UNIT_US, "[1.50 µs]"},
+		{2500 * time.Millisecond, TIME_UNIT_S, "[2.50 s]"},
+	}
+
+	for _, tc := range cases {
+		if actual := getProcessingTime(tc.duration, tc.unit); actual != tc.expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", tc.expected, actual)
+		}
+	}
+}
+
+func TestFieldSeparator(t *testing.T) {
+	container := Container{Status: STATUS_INFO, Id: "5f322ac4ba", Info: "user created"}
+	format := []LogFormat{FORMAT_STATUS, FORMAT_ID, FORMAT_SOURCE, FORMAT_INFO}
+
+	cases := map[string]string{
+		"":    "INFO 5f322ac4ba user created",
+		"\t":  "INFO\t5f322ac4ba\tuser created",
+		" | ": "INFO | 5f322ac4ba | user created",
+	}
+
+	for separator, expected := range cases {
+		l := &Logger{Format: format, Options: Options{FieldSeparator: separator}}
+		if actual := l.formatText(&container, l.Format, false); actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+	}
+}
+
+func TestFormatEntry(t *testing.T) {
+	container := Container{
+		Status:    STATUS_WARN,
+		Info:      "disk almost full",
+		Timestamp: time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	format := []LogFormat{FORMAT_TIMESTAMP, FORMAT_STATUS, FORMAT_INFO}
+
+	cases := map[OutputFormat]string{
+		OUTPUT_TEXT: "2023-06-01T12:00:00Z WARN disk almost full",
+		OUTPUT_JSON: `{"timestamp":"2023-06-01T12:00:00Z","status":"WARN","info":"disk almost full"}`,
+	}
+
+	for outputFormat, expected := range cases {
+		l := &Logger{Format: format, Options: Options{OutputFormat: outputFormat}}
+		if actual := l.FormatEntry(container); actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+	}
+}
+
+func TestGetFields(t *testing.T) {
+	fields := map[string]any{
+		"user_id": 42,
+		"action":  "sign in",
+		"empty":   "",
+		"ok":      true,
+	}
+
+	expected := `action="sign in" empty="" ok=true user_id=42`
+	actual := getFields(fields)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestFieldsTextMatchesJSON(t *testing.T) {
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_FIELDS},
+		Options: Options{TimestampLayout: "2006-01-02 15:04:05"},
+	}
+
+	c := Container{Fields: map[string]any{
+		"elapsed": 1500 * time.Microsecond,
+		"at":      time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+		"tags":    []string{"a", "b"},
+	}}
+
+	// Times and durations are rendered the same way in both outputs
+	expected := `at="2023-06-01 12:00:00" elapsed="1.50 ms" tags="[\"a\",\"b\"]"`
+	if actual := l.FormatEntry(c); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	l.Options.OutputFormat = OUTPUT_JSON
+	expected = `{"fields":{"at":"2023-06-01 12:00:00","elapsed":"1.50 ms","tags":["a","b"]}}`
+	if actual := l.FormatEntry(c); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	joined := errors.Join(errors.New("first"), errors.Join(errors.New("second"), nil))
+
+	// Joined errors are listed one by one instead of as one item with an embedded newline
+	expected := "[1] disk full [2] first [3] second [4] timeout"
+	actual := getErrors([]error{errors.New("disk full"), nil, joined, errors.New("timeout")})
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	if actual := getErrors(nil); actual != "" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "", actual)
+	}
+}
+
+func TestOutputFolderPathWithoutTrailingSeparator(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	format := []LogFormat{FORMAT_INFO}
+	options := Options{OutputToFile: true, OutputFolderPath: dir}
+	l, err := NewLogger(format, options, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	expected := []string{time.Now().Format("2006_01_02") + ".log"}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	var actual []string
+	for _, entry := range entries {
+		actual = append(actual, entry.Name())
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestRotateReopensMovedFile(t *testing.T) {
+	dir := t.TempDir() + string(os.PathSeparator)
+	l := &Logger{Options: Options{OutputToFile: true, OutputFolderPath: dir}}
+	defer l.closeLogFile()
+
+	ts := time.Now()
+	l.writeLogToFile("first", &Container{Timestamp: ts})
+
+	// Simulate an external tool moving the log file away
+	name := l.logFileName(ts)
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	if err := l.Rotate(); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	l.writeLogToFile("second", &Container{Timestamp: ts})
+
+	expected := "second\n"
+	actual, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if string(actual) != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, string(actual))
+	}
+
+	expected = "first\n"
+	actual, _ = os.ReadFile(name + ".1")
+	if string(actual) != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, string(actual))
+	}
+}
+
+func TestSampleRates(t *testing.T) {
+	l := &Logger{Options: Options{SampleRates: map[LogStatus]int{STATUS_INFO: 100}}}
+
+	passed := map[LogStatus]int{}
+	for i := 0; i < 1000; i++ {
+		for _, status := range []LogStatus{STATUS_INFO, STATUS_ERROR} {
+			if l.sample(status) {
+				passed[status]++
+			}
+		}
+	}
+
+	expected := map[LogStatus]int{STATUS_INFO: 10, STATUS_ERROR: 1000}
+	for status, count := range expected {
+		if passed[status] != count {
+			t.Errorf("Unexpected result for %s.\nExpected:\n%#v\nGot:\n%#v", logStatustoString[status], count, passed[status])
+		}
+	}
+
+	if l.sampledOut != 990 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 990, l.sampledOut)
+	}
+}
+
+func TestGetGoroutines(t *testing.T) {
+	actual := getGoroutines()
+	if !strings.HasPrefix(actual, "goroutines=") || actual == "goroutines=0" {
+		t.Errorf("Unexpected result: %#v", actual)
+	}
+}
+
+func TestGetMemStatsIsCached(t *testing.T) {
+	l := &Logger{Options: Options{MemStatsInterval: time.Hour}}
+
+	first := l.getMemStats()
+	if !strings.HasPrefix(first, "alloc=") || !strings.Contains(first, " sys=") {
+		t.Errorf("Unexpected result: %#v", first)
+	}
+
+	read := l.memStatsRead
+	l.getMemStats()
+	if l.memStatsRead != read {
+		t.Errorf("Unexpected result: memory statistics were read again within the caching interval")
+	}
+}
+
+func TestGetMemStatsConcurrentFormatEntry(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_MEMSTATS, FORMAT_INFO}, Options{
+		OutputToStdout:   true,
+		Stdout:           &capturedOutput,
+		MemStatsInterval: time.Nanosecond,
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	// FormatEntry runs on the calling goroutine while processLogs formats the logged entries (run with -race)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			logger.FormatEntry(Container{Info: "formatted"})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		logger.Info("logged")
+	}
+	<-done
+	logger.Close()
+
+	if lines := strings.Count(capturedOutput.String(), "\n"); lines != 100 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 100, lines)
+	}
+}
+
+func TestStatusCounts(t *testing.T) {
+	l := &Logger{StatusCounters: make(map[LogStatus]int)}
+
+	for _, status := range []LogStatus{STATUS_INFO, STATUS_ERROR, STATUS_INFO} {
+		l.processEntry(Container{Status: status})
+	}
+
+	counts := l.StatusCounts()
+	expected := map[LogStatus]int{STATUS_INFO: 2, STATUS_ERROR: 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, counts)
+	}
+
+	// The returned map is a copy
+	counts[STATUS_INFO] = 100
+	if count := l.StatusCounters[STATUS_INFO]; count != 2 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 2, count)
+	}
+}
+
+func TestStatusCountersConcurrentAccess(t *testing.T) {
+	l := &Logger{StatusCounters: make(map[LogStatus]int)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			l.processEntry(Container{Status: STATUS_INFO})
+		}
+	}()
+
+	// Reading while entries are processed must not race (run with -race)
+	for i := 0; i < 100; i++ {
+		l.GetLogStatusCounters()
+		l.StatusCounts()
+	}
+	<-done
+
+	if count := l.StatusCounts()[STATUS_INFO]; count != 1000 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 1000, count)
+	}
+}
+
+func TestLogStatusString(t *testing.T) {
+	for _, status := range []LogStatus{STATUS_TRACE, STATUS_INFO, STATUS_WARN, STATUS_ERROR, STATUS_FATAL} {
+		parsed, err := ParseLogStatus(strings.ToLower(status.String()))
+		if err != nil {
+			t.Fatalf("Unexpected result: " + err.Error())
+		}
+		if parsed != status {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", status, parsed)
+		}
+	}
+
+	if actual := LogStatus(99).String(); actual != "LogStatus(99)" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "LogStatus(99)", actual)
+	}
+
+	if _, err := ParseLogStatus("VERBOSE"); !errors.Is(err, ErrInvalidSeverity) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrInvalidSeverity, err)
+	}
+}
+
+func TestLogStatusOrdering(t *testing.T) {
+	ordered := []LogStatus{STATUS_TRACE, STATUS_INFO, STATUS_WARN, STATUS_ERROR, STATUS_FATAL}
+
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i-1] >= ordered[i] {
+			t.Errorf("Unexpected result.\nExpected:\n%s < %s\nGot:\n%d >= %d",
+				logStatustoString[ordered[i-1]], logStatustoString[ordered[i]], ordered[i-1], ordered[i])
+		}
+	}
+}
+
+func TestWorstStatus(t *testing.T) {
+	l := &Logger{StatusCounters: make(map[LogStatus]int)}
+
+	if actual := l.WorstStatus(); actual != STATUS_TRACE {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", STATUS_TRACE, actual)
+	}
+
+	for _, status := range []LogStatus{STATUS_WARN, STATUS_ERROR, STATUS_INFO} {
+		l.processEntry(Container{Status: status})
+	}
+
+	if actual := l.WorstStatus(); actual != STATUS_ERROR {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", STATUS_ERROR, actual)
+	}
+}
+
+func TestContinuationMode(t *testing.T) {
+	container := Container{Status: STATUS_INFO, Id: "5f322ac4ba", ProcessedData: map[string]int{"age": 30}}
+	format := []LogFormat{FORMAT_STATUS, FORMAT_ID, FORMAT_PROCESSED_DATA}
+
+	cases := map[ContinuationMode]string{
+		CONTINUATION_NONE:   "INFO 5f322ac4ba >Processed Data:\n{\n  \"age\": 30\n}",
+		CONTINUATION_MARKER: "INFO 5f322ac4ba >Processed Data:\n| {\n|   \"age\": 30\n| }",
+		CONTINUATION_ID:     "INFO 5f322ac4ba >Processed Data:\n5f322ac4ba | {\n5f322ac4ba |   \"age\": 30\n5f322ac4ba | }",
+	}
+
+	for mode, expected := range cases {
+		l := &Logger{Format: format, Options: Options{ContinuationMode: mode}}
+		actual := l.formatText(&container, l.Format, false)
+		if actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+	}
+}
+
+func TestEmptyFieldPlaceholder(t *testing.T) {
+	container := Container{Status: STATUS_INFO, Source: "handler/user"}
+	format := []LogFormat{FORMAT_STATUS, FORMAT_ID, FORMAT_SOURCE, FORMAT_ERROR}
+
+	cases := map[string]string{
+		"":  "INFO handler/user",
+		"-": "INFO - handler/user -",
+	}
+
+	for placeholder, expected := range cases {
+		l := &Logger{Format: format, Options: Options{EmptyFieldPlaceholder: placeholder}}
+		actual := l.formatText(&container, l.Format, false)
+		if actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+	}
+}
+
+func TestNewLoggerSentinelErrors(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "file.log")
+	if err := os.WriteFile(filePath, nil, 0644); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	_, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{OutputToFile: true, OutputFolderPath: filePath}, Container{})
+	if !errors.Is(err, ErrOutputPathNotDirectory) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrOutputPathNotDirectory, err)
+	}
+
+	_, err = NewLogger([]LogFormat{FORMAT_INFO}, Options{SampleRates: map[LogStatus]int{LogStatus(99): 2}}, Container{})
+	if !errors.Is(err, ErrInvalidSeverity) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrInvalidSeverity, err)
+	}
+
+	_, err = NewLogger([]LogFormat{FORMAT_INFO, LogFormat(99)}, Options{}, Container{})
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrInvalidFormat, err)
+	}
+}
+
+func TestDuplicateFormatWarning(t *testing.T) {
+	var warnings []error
+
+	// Repeated items are accepted, the warning goes to the error handler instead of STDOUT
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO, FORMAT_STATUS, FORMAT_INFO}, Options{
+		ErrorHandler: func(err error) { warnings = append(warnings, err) },
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	logger.Close()
+
+	if len(warnings) != 1 || !errors.Is(warnings[0], ErrDuplicateFormat) {
+		t.Fatalf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrDuplicateFormat, warnings)
+	}
+	if expected, actual := "failed to validate format: format item configured more than once: INFO (index 2)", warnings[0].Error(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on windows")
+	}
+
+	dir := t.TempDir()
+
+	// 0600 is not reduced by common umasks
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		OutputToFile:     true,
+		OutputFolderPath: dir,
+		FileMode:         0600,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	logger.Close()
+
+	info, err := os.Stat(logger.logFileName(time.Now()))
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if expected, actual := os.FileMode(0600), info.Mode().Perm(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestCreateFolderIfMissing(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "logs")
+
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		OutputToFile:          true,
+		OutputFolderPath:      dir,
+		CreateFolderIfMissing: true,
+		FolderMode:            0700,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	logger.Close()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if expected, actual := os.FileMode(0700), info.Mode().Perm(); runtime.GOOS != "windows" && expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	content, err := os.ReadFile(logger.logFileName(time.Now()))
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if expected, actual := "started\n", string(content); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestNanoTimestamps(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_TIMESTAMP}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+		NanoTimestamps: true,
+	}, Container{Timestamp: time.Date(2023, 6, 1, 12, 0, 0, 123456789, time.UTC)})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	logger.Info("first")
+	logger.Info("second")
+	logger.Close()
+
+	lines := strings.Split(strings.TrimSpace(capturedOutput.String()), "\n")
+	if expected, actual := "2023-06-01T12:00:00.123456789Z", lines[0]; expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Two rapid entries are distinguishable
+	if len(lines) != 3 || lines[1] == lines[2] {
+		t.Errorf("Unexpected result: timestamps of rapid entries are not distinct: %#v", lines)
+	}
+}
+
+func TestSynchronous(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+		Synchronous:    true,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	defer logger.Close()
+
+	// The entries are written before Entry returns, no Flush or Close required
+	logger.Warn("disk almost full")
+
+	expected := "INFO started\nWARN disk almost full\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestSynchronousConcurrentEntries(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+		Synchronous:    true,
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("entry")
+			}
+		}()
+	}
+	wg.Wait()
+	logger.Close()
+
+	if expected, actual := 1000, strings.Count(capturedOutput.String(), "entry\n"); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestFileFormat(t *testing.T) {
+	var capturedOutput bytes.Buffer
+	dir := t.TempDir() + string(os.PathSeparator)
+
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		OutputToStdout:   true,
+		Stdout:           &capturedOutput,
+		OutputToFile:     true,
+		OutputFolderPath: dir,
+		FileFormat:       []LogFormat{FORMAT_STATUS, FORMAT_SOURCE, FORMAT_INFO},
+		FileOutputFormat: OUTPUT_JSON,
+	}, Container{Status: STATUS_INFO, Source: "main", Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	logger.Close()
+
+	expected := "INFO started\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	content, err := os.ReadFile(logger.logFileName(time.Now()))
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	expected = `{"status":"INFO","source":"main","info":"started"}` + "\n"
+	if actual := string(content); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// The file format is validated like the format
+	_, err = NewLogger([]LogFormat{FORMAT_INFO}, Options{FileFormat: []LogFormat{LogFormat(99)}}, Container{})
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrInvalidFormat, err)
+	}
+}
+
+func TestLinePrefixAndSuffix(t *testing.T) {
+	var capturedOutput bytes.Buffer
+	dir := t.TempDir() + string(os.PathSeparator)
+
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO, FORMAT_DATA}, Options{
+		OutputToStdout:   true,
+		Stdout:           &capturedOutput,
+		OutputToFile:     true,
+		OutputFolderPath: dir,
+		LinePrefix:       "[app] ",
+		LineSuffix:       " ;",
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	logger.Warn("disk almost full")
+	logger.Close()
+
+	expected := "[app] INFO started ;\n[app] WARN disk almost full ;\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	content, err := os.ReadFile(logger.logFileName(time.Now()))
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if actual := string(content); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestCompactProcessedData(t *testing.T) {
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_STATUS, FORMAT_INFO, FORMAT_PROCESSED_DATA},
+		Options: Options{CompactProcessedData: true},
+	}
+
+	expected := `INFO user created {"age":30,"name":"John Doe"}`
+	actual := l.FormatEntry(Container{
+		Status:        STATUS_INFO,
+		Info:          "user created",
+		ProcessedData: map[string]any{"name": "John Doe", "age": 30},
+	})
+	if expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestEscapeNewlines(t *testing.T) {
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_STATUS, FORMAT_INFO, FORMAT_ERROR, FORMAT_PROCESSED_DATA},
+		Options: Options{EscapeNewlines: true},
+	}
+
+	expected := `ERROR first line\r\nsecond line stack:\n  main.go:12 >Processed Data:\n{\n  "age": 30\n}`
+	actual := l.FormatEntry(Container{
+		Status:        STATUS_ERROR,
+		Info:          "first line\r\nsecond line",
+		Error:         "stack:\n  main.go:12",
+		ProcessedData: map[string]int{"age": 30},
+	})
+	if expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// The JSON output escapes line breaks anyway and is unchanged
+	l.Options.OutputFormat = OUTPUT_JSON
+	if actual := l.FormatEntry(Container{Status: STATUS_INFO, Info: "a\nb"}); !strings.Contains(actual, `"info":"a\nb"`) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", `"info":"a\nb"`, actual)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var capturedOutput bytes.Buffer
+	var seen []string
+
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+		Hooks: []func(Container){
+			func(c Container) {
+				// The entry is not written yet when the hook is called
+				seen = append(seen, c.Status.String()+" "+c.Info+" "+fmt.Sprint(strings.Count(capturedOutput.String(), "\n")))
+			},
+		},
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	logger.Warn("disk almost full")
+	logger.Error("request failed", errors.New("timeout"))
+	logger.Close()
+
+	expected := []string{"INFO started 0", "WARN disk almost full 1", "ERROR request failed 2"}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, seen)
+	}
+}
+
+func TestNewLoggerWithoutFileOutputSkipsFolderChecks(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		OutputToStdout:   true,
+		Stdout:           &capturedOutput,
+		OutputFolderPath: "folder/not/existing/",
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	logger.Close()
+
+	if _, err := os.Stat("testfile.tmp"); !os.IsNotExist(err) {
+		t.Errorf("Unexpected result: testfile.tmp was created")
+	}
+}
+
+func TestCloseWritesSummary(t *testing.T) {
+	dir := t.TempDir() + string(os.PathSeparator)
+
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		OutputToFile:     true,
+		OutputFolderPath: dir,
+		SummaryOnClose:   true,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	logger.Entry(Container{Status: STATUS_ERROR, Info: "failed"})
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected result on second Close: " + err.Error())
+	}
+
+	content, err := os.ReadFile(logger.logFileName(time.Now()))
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 3, lines)
+	}
+
+	expected := "entries [INFO: 1] [ERROR: 1], 0 sampled out, 0 dropped"
+	if !strings.HasPrefix(lines[2], "INFO Logger closed after ") || !strings.HasSuffix(lines[2], ": 2 "+expected) {
+		t.Errorf("Unexpected result.\nExpected suffix:\n%#v\nGot:\n%#v", expected, lines[2])
+	}
+}
+
+func TestErrorPrecedence(t *testing.T) {
+	err := errors.New("connection refused")
+
+	cases := []struct {
+		precedence ErrorPrecedence
+		container  Container
+		expected   string
+	}{
+		{ERROR_PREFER_ERR, Container{Error: "query failed"}, "query failed"},
+		{ERROR_PREFER_ERR, Container{Err: err}, "connection refused"},
+		{ERROR_PREFER_ERR, Container{Error: "query failed", Err: err}, "connection refused"},
+		{ERROR_CONCAT, Container{Error: "query failed"}, "query failed"},
+		{ERROR_CONCAT, Container{Err: err}, "connection refused"},
+		{ERROR_CONCAT, Container{Error: "query failed", Err: err}, "query failed: connection refused"},
+	}
+
+	for _, c := range cases {
+		l := &Logger{Format: []LogFormat{FORMAT_ERROR}, Options: Options{ErrorPrecedence: c.precedence}}
+		actual := l.formatText(&c.container, l.Format, false)
+		if actual != c.expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", c.expected, actual)
+		}
+	}
+}
+
+func TestEntryAfterClose(t *testing.T) {
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{}, Container{Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	// Must neither panic nor block
+	logger.Entry(Container{Info: "late"})
+
+	if expected, actual := 1, logger.LateEntryCount(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	if err := logger.Rotate(); !errors.Is(err, ErrLoggerClosed) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrLoggerClosed, err)
+	}
+}
+
+func TestCloseRacingEntries(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		OutputToStdout:    true,
+		Stdout:            &capturedOutput,
+		ChannelBufferSize: 10,
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	const goroutines, entries = 20, 200
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < entries; j++ {
+				logger.Info("entry")
+			}
+		}()
+	}
+
+	close(start)
+	logger.Close()
+	wg.Wait()
+
+	// Every entry was either written before Close or counted as late entry
+	written := strings.Count(capturedOutput.String(), "entry\n")
+	if expected, actual := goroutines*entries, written+logger.LateEntryCount(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestCloseReturnsWriteError(t *testing.T) {
+	dir := t.TempDir() + string(os.PathSeparator)
+
+	// Make the log file path unusable by putting a directory in its place
+	name := (&Logger{Options: Options{OutputFolderPath: dir}}).logFileName(time.Now())
+	if err := os.Mkdir(name, 0755); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	logger, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{OutputToFile: true, OutputFolderPath: dir}, Container{Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	if err := logger.Close(); err == nil {
+		t.Errorf("Unexpected result: Close should return the write error")
+	}
+}
+
+func TestDropWhenFull(t *testing.T) {
+	var reported []error
+
+	logger := &Logger{
+		Format:  []LogFormat{FORMAT_INFO},
+		LogChan: make(chan Container, 2),
+		Options: Options{
+			DropWhenFull: true,
+			ErrorHandler: func(err error) { reported = append(reported, err) },
+		},
+	}
+
+	// Nobody reads the channel, so everything beyond its capacity is dropped without blocking
+	for i := 0; i < 5; i++ {
+		logger.Entry(Container{Info: "burst"})
+	}
+
+	if actual := logger.DroppedCount(); actual != 3 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 3, actual)
+	}
+
+	// Every dropped entry is reported
+	if len(reported) != 3 || !errors.Is(reported[0], ErrLogQueueFull) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", ErrLogQueueFull, reported)
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	var first, second bytes.Buffer
+
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		Outputs: []io.Writer{&first, &second},
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	logger.Entry(Container{Status: STATUS_WARN, Info: "disk almost full"})
+	logger.Close()
+
+	expected := "INFO started\nWARN disk almost full\n"
+	for _, actual := range []string{first.String(), second.String()} {
+		if actual != expected {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+		}
+	}
+}
+
+// A writer which rejects every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestOutputWriteErrors(t *testing.T) {
+	var (
+		reported []error
+		healthy  bytes.Buffer
+	)
+
+	l := &Logger{
+		Format: []LogFormat{FORMAT_INFO},
+		Options: Options{
+			OutputToStdout: true,
+			Stdout:         failingWriter{},
+			Outputs:        []io.Writer{&healthy, failingWriter{}},
+			ErrorHandler:   func(err error) { reported = append(reported, err) },
+		},
+	}
+
+	l.writeEntry(Container{Info: "user created"})
+
+	// Every failing destination is reported by name and the healthy output is still written
+	expected := []string{"failed to write to STDOUT: connection reset", "failed to write to output 1: connection reset"}
+	actual := make([]string, 0, len(reported))
+	for _, err := range reported {
+		actual = append(actual, err.Error())
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+	if healthy.String() != "user created\n" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "user created\n", healthy.String())
+	}
+}
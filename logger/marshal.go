@@ -0,0 +1,267 @@
+package logger
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Maximum nesting depth which is normalized, deeper (e.g. cyclic) values are replaced by a placeholder
+const maxNormalizeDepth = 32
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	normalizedDepthText = "<max depth exceeded>"
+)
+
+// A JSON object whose members keep their order when marshaled (used for structs)
+type orderedObject []orderedMember
+
+type orderedMember struct {
+	key   string
+	value any
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(member.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Returns the layout which is used for the timestamp field and time.Time values inside of structured data.
+//
+// Returns:
+//   - string: Options.TimestampLayout, or time.RFC3339 (time.RFC3339Nano with Options.NanoTimestamps) if it is empty
+func (l *Logger) timestampLayout() string {
+	if l.Options.NanoTimestamps {
+		return stringOrDefault(l.Options.TimestampLayout, time.RFC3339Nano)
+	}
+	return stringOrDefault(l.Options.TimestampLayout, time.RFC3339)
+}
+
+// Prepares structured data for JSON serialization, so time values are rendered consistently with the rest of the log line.
+//
+// The default JSON marshaling renders time.Duration values as nanosecond integers and time.Time values in RFC3339.
+// This function walks the data and replaces durations by Options.ProcessingTimeUnit (e.g. "1.50 ms", like the processing time)
+// and times by the logger's timestamp layout. Structs are converted to ordered objects honoring the `json` tags,
+// so the field order of the regular marshaling is kept. Values implementing json.Marshaler or
+// encoding.TextMarshaler are left untouched. Values of map keys and struct fields listed in
+// Options.RedactKeys are replaced by "***".
+//
+// Parameters:
+//   - data: any - the data to normalize
+//
+// Returns:
+//   - any: the normalized data, ready for json.Marshal
+func (l *Logger) normalizeData(data any) any {
+	if data == nil {
+		return nil
+	}
+	return l.normalizeValue(reflect.ValueOf(data), 0)
+}
+
+// Normalizes a single value, see normalizeData.
+//
+// Parameters:
+//   - v: reflect.Value - the value to normalize
+//   - depth: int - the current nesting depth
+//
+// Returns:
+//   - any: the normalized value
+func (l *Logger) normalizeValue(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxNormalizeDepth {
+		return normalizedDepthText
+	}
+
+	switch v.Type() {
+	case durationType:
+		return formatDuration(time.Duration(v.Int()), l.Options.ProcessingTimeUnit)
+	case timeType:
+		return v.Interface().(time.Time).Format(l.timestampLayout())
+	}
+
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return l.normalizeValue(v.Elem(), depth+1)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := mapKeyString(iter.Key())
+			if l.isRedactedKey(key) {
+				m[key] = redactedValue
+				continue
+			}
+			m[key] = l.normalizeValue(iter.Value(), depth+1)
+		}
+		return m
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// Byte slices keep their base64 encoding
+			return v.Interface()
+		}
+		return l.normalizeList(v, depth)
+
+	case reflect.Array:
+		return l.normalizeList(v, depth)
+
+	case reflect.Struct:
+		obj := orderedObject{}
+		l.normalizeStruct(v, depth, &obj)
+		return obj
+	}
+
+	return v.Interface()
+}
+
+// Normalizes the elements of a slice or array.
+//
+// Parameters:
+//   - v: reflect.Value - the slice or array
+//   - depth: int - the current nesting depth
+//
+// Returns:
+//   - []any: the normalized elements
+func (l *Logger) normalizeList(v reflect.Value, depth int) []any {
+	list := make([]any, v.Len())
+	for i := range list {
+		list[i] = l.normalizeValue(v.Index(i), depth+1)
+	}
+	return list
+}
+
+// Appends the exported fields of a struct to an ordered object.
+//
+// The `json` tag is honored for the field name, "-" and "omitempty". Fields of embedded structs
+// without a tag name are inlined like encoding/json does.
+//
+// Parameters:
+//   - v: reflect.Value - the struct
+//   - depth: int - the current nesting depth
+//   - obj: *orderedObject - the object to append the fields to
+func (l *Logger) normalizeStruct(v reflect.Value, depth int, obj *orderedObject) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			embedded := fv
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				l.normalizeStruct(embedded, depth+1, obj)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyValue(fv) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if l.isRedactedKey(name) {
+			*obj = append(*obj, orderedMember{key: name, value: redactedValue})
+			continue
+		}
+
+		*obj = append(*obj, orderedMember{key: name, value: l.normalizeValue(fv, depth+1)})
+	}
+}
+
+// Returns the string representation of a map key, matching encoding/json.
+//
+// Parameters:
+//   - key: reflect.Value - the map key
+//
+// Returns:
+//   - string: the key as string
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(key.Interface())
+}
+
+// Reports whether a value is empty in the sense of the "omitempty" option of encoding/json.
+//
+// Parameters:
+//   - v: reflect.Value - the value to check
+//
+// Returns:
+//   - bool: true if the value is empty
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNormalizeDataTimeValues(t *testing.T) {
+	type inner struct {
+		Took time.Duration `json:"took"`
+	}
+	type event struct {
+		inner
+		At       time.Time      `json:"at"`
+		Timeout  time.Duration  `json:"timeout"`
+		Name     string         `json:"name,omitempty"`
+		Skipped  string         `json:"-"`
+		Details  map[string]any `json:"details"`
+		internal int
+	}
+
+	l := &Logger{}
+	data := event{
+		inner:    inner{Took: 1500 * time.Microsecond},
+		At:       time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+		Timeout:  2 * time.Second,
+		Skipped:  "secret",
+		Details:  map[string]any{"retry": []time.Duration{time.Millisecond}},
+		internal: 1,
+	}
+
+	expected := "{\"took\":\"1.50 ms\",\"at\":\"2023-06-01T12:00:00Z\",\"timeout\":\"2000.00 ms\",\"details\":{\"retry\":[\"1.00 ms\"]}}"
+	raw, err := json.Marshal(l.normalizeData(data))
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	actual := string(raw)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestNormalizeDataDurationUnit(t *testing.T) {
+	// Durations use the unit of the processing time
+	l := &Logger{Options: Options{ProcessingTimeUnit: TIME_UNIT_US}}
+	data := map[string]time.Duration{"took": 1500 * time.Microsecond, "idle": 0}
+
+	expected := "{\"idle\":\"0.00 µs\",\"took\":\"1500.00 µs\"}"
+	raw, err := json.Marshal(l.normalizeData(data))
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	if actual := string(raw); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestNormalizeDataKeepsProcessedDataOutput(t *testing.T) {
+	data := map[string]interface{}{
+		"name": "John Doe",
+		"tags": []string{"go"},
+	}
+
+	expected, _ := getProcessedData(data, false)
+	actual, _ := getProcessedData((&Logger{}).normalizeData(data), false)
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestProcessedDataUnserializable(t *testing.T) {
+	var reported []error
+
+	l := &Logger{
+		Format: []LogFormat{FORMAT_INFO, FORMAT_PROCESSED_DATA},
+		Options: Options{
+			ErrorHandler: func(err error) {
+				reported = append(reported, err)
+			},
+		},
+	}
+	c := Container{Info: "job", ProcessedData: map[string]any{"done": make(chan int)}}
+
+	expected := "job <unserializable: json: unsupported type: chan int>"
+	if actual := l.FormatEntry(c); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	l.Options.OutputFormat = OUTPUT_JSON
+	expected = `{"info":"job","processed_data":"<unserializable: json: unsupported type: chan int>"}`
+	if actual := l.FormatEntry(c); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// The underlying error is routed to the error handler instead of being logged inline
+	if len(reported) != 2 {
+		t.Fatalf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 2, len(reported))
+	}
+	var typeErr *json.UnsupportedTypeError
+	if !errors.As(reported[0], &typeErr) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", typeErr, reported[0])
+	}
+}
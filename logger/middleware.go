@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Wraps a http.ResponseWriter to record the response status and size for HTTPMiddleware
+type responseRecorder struct {
+	http.ResponseWriter
+	status int   // Status code of the response, 0 until the header is written
+	bytes  int64 // Number of body bytes written
+}
+
+// Records the status code and writes the header.
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Writes the body, implicitly with status 200 if no header was written before.
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flushes the buffered response if the wrapped writer supports it (e.g. for server-sent events).
+func (w *responseRecorder) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Takes over the connection if the wrapped writer supports it (e.g. for websocket upgrades).
+//
+// A hijacked response is logged with status 101 (Switching Protocols) unless a header was written before.
+func (w *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %T does not implement http.Hijacker", http.ErrNotSupported, w.ResponseWriter)
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+// Returns the wrapped writer, used by http.ResponseController.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Maps the status code of a response to the status of the access log entry.
+//
+// Parameters:
+//   - status: int - the HTTP status code
+//
+// Returns:
+//   - LogStatus: STATUS_ERROR for 5xx, STATUS_WARN for 4xx and STATUS_INFO otherwise
+func httpStatusToLogStatus(status int) LogStatus {
+	switch {
+	case status >= 500:
+		return STATUS_ERROR
+	case status >= 400:
+		return STATUS_WARN
+	default:
+		return STATUS_INFO
+	}
+}
+
+// Returns a handler which logs an access log entry for every request handled by next.
+//
+// The request is timed and the response status and size are recorded by wrapping the
+// http.ResponseWriter. After next returned, an entry with the method and path as info, the request as
+// Container.HttpRequest, the duration as Container.ProcessingTime and the response status and size as
+// Container.HttpStatus and Container.ResponseBytes is logged (use FORMAT_HTTP_STATUS and
+// FORMAT_RESPONSE_BYTES to log them). Responses with a 5xx status are logged as ERROR, 4xx as WARN and everything else as INFO.
+// If the format contains FORMAT_HTTP_BODY, the request body is recorded before next reads it.
+// The wrapped writer keeps supporting http.Flusher and http.Hijacker, so upgrade handlers (e.g. websockets) work behind the middleware.
+//
+// Parameters:
+//   - next: http.Handler - the handler which serves the requests
+//
+// Returns:
+//   - http.Handler: the logging handler
+//
+// Example:
+//
+//	http.ListenAndServe(":8080", appLogger.HTTPMiddleware(mux))
+//	// INFO GET /users 200 512 bytes [1.25 ms]
+func (l *Logger) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w}
+
+		// The handler consumes the body, so it is recorded (and restored for the handler) beforehand
+		var body string
+		if l.hasFormat(FORMAT_HTTP_BODY) {
+			body = l.captureHttpBody(r)
+		}
+
+		next.ServeHTTP(recorder, r)
+
+		// A handler which writes nothing responds with 200
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		l.entry(Container{
+			Status:         httpStatusToLogStatus(status),
+			Info:           r.Method + " " + r.URL.Path,
+			HttpRequest:    r,
+			HttpBody:       body,
+			ProcessingTime: time.Since(start),
+			HttpStatus:     status,
+			ResponseBytes:  recorder.bytes,
+			Timestamp:      start,
+		}, 1)
+	})
+}
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO, FORMAT_HTTP_STATUS, FORMAT_RESPONSE_BYTES}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "[]")
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {})
+
+	server := httptest.NewServer(l.HTTPMiddleware(mux))
+	defer server.Close()
+
+	for _, path := range []string{"/users?page=2", "/missing", "/fail", "/empty"} {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Unexpected result: " + err.Error())
+		}
+		response.Body.Close()
+	}
+	l.Close()
+
+	expected := strings.Join([]string{
+		"INFO started",
+		"INFO GET /users 200 2 bytes",
+		"WARN GET /missing 404 19 bytes",
+		"ERROR GET /fail 500 5 bytes",
+		"INFO GET /empty 200", // Empty responses skip the size
+	}, "\n") + "\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestResponseRecorderSupportsResponseController(t *testing.T) {
+	recorder := &responseRecorder{ResponseWriter: httptest.NewRecorder()}
+
+	if err := http.NewResponseController(recorder).Flush(); err != nil {
+		t.Errorf("Unexpected result: " + err.Error())
+	}
+}
+
+func TestHttpStatusAndResponseBytesJSON(t *testing.T) {
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_INFO, FORMAT_HTTP_STATUS, FORMAT_RESPONSE_BYTES},
+		Options: Options{OutputFormat: OUTPUT_JSON},
+	}
+
+	expected := `{"info":"GET /users","http_status":200,"response_bytes":512}`
+	if actual := l.FormatEntry(Container{Info: "GET /users", HttpStatus: 200, ResponseBytes: 512}); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Zero values are omitted
+	expected = `{"info":"started"}`
+	if actual := l.FormatEntry(Container{Info: "started"}); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestHTTPMiddlewareRecordsBody(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_INFO, FORMAT_HTTP_BODY}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	// The handler consumes the complete body
+	var received string
+	handler := l.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+	}))
+
+	request := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"John"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	l.Close()
+
+	if expected := `{"name":"John"}`; received != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, received)
+	}
+	expected := "POST /users {\"name\":\"John\"}\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO, FORMAT_HTTP_STATUS}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	// An upgrade handler takes over the connection and answers on it directly
+	server := httptest.NewServer(l.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Errorf("Unexpected result: %T does not implement http.Hijacker", w)
+			return
+		}
+		conn, rw, err := hijacker.Hijack()
+		if err != nil {
+			t.Errorf("Unexpected result: " + err.Error())
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+		rw.Flush()
+	})))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/ws")
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	l.Close()
+
+	if string(body) != "ok" {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "ok", string(body))
+	}
+	expected := "INFO GET /ws 101\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestResponseRecorderHijackNotSupported(t *testing.T) {
+	// httptest.ResponseRecorder cannot be hijacked
+	recorder := &responseRecorder{ResponseWriter: httptest.NewRecorder()}
+
+	if _, _, err := recorder.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", http.ErrNotSupported, err)
+	}
+}
@@ -0,0 +1,169 @@
+package logger
+
+// A processing stage of the log pipeline.
+//
+// Each stage receives the log entry container and returns the (possibly modified) container
+// together with a flag telling whether the entry shall be passed on to the next stage.
+// Returning false drops the entry.
+//
+// Example:
+//
+//	// Drop all entries of a noisy source
+//	func(c logger.Container) (logger.Container, bool) {
+//	    return c, c.Source != "handler/health"
+//	}
+type Stage func(Container) (Container, bool)
+
+// Returns the processing pipeline of the logger, building it on first use.
+//
+// The stages are executed in this order:
+//  1. min status: skips entries below Options.MinStatus
+//  2. filter: skips entries rejected by Options.Filter
+//  3. count: increments the status counters and tracks the worst status (every remaining entry is counted)
+//  4. sample: drops entries according to Options.SampleRates
+//  5. rate limit: suppresses entries exceeding Options.RateLimits
+//  6. redact: masks sensitive values in Container.Data according to Options.RedactDataKeys
+//  7. Options.Pipeline: the custom stages in the order they are configured
+//  8. aggregate: absorbs the entry into an aggregate if Options.AggregateWindow is set
+//  9. repeat: suppresses repeats of the previous line according to Options.RepeatWindow
+//
+// Entries passing all stages are written to the outputs.
+//
+// Returns:
+//   - []Stage: the stages of the pipeline
+func (l *Logger) pipeline() []Stage {
+	if l.stages == nil {
+		l.dataRedactor = dataRedactor(l.Options.RedactDataKeys)
+		l.stages = append(l.stages, l.minStatusStage, l.filterStage, l.countStage, l.sampleStage, l.rateLimitStage, l.redactStage)
+		l.stages = append(l.stages, l.Options.Pipeline...)
+		l.stages = append(l.stages, l.aggregateStage, l.repeatStage)
+	}
+	return l.stages
+}
+
+// Processes a single log entry.
+//
+// The entry passes through the stages of the pipeline and is written to the configured
+// outputs unless a stage dropped it.
+//
+// Parameters:
+//   - c: Container - the log entry container
+func (l *Logger) processEntry(c Container) {
+	for _, stage := range l.pipeline() {
+		var pass bool
+		if c, pass = stage(c); !pass {
+			return
+		}
+	}
+
+	l.writeEntry(c)
+}
+
+// Skips entries whose status is numerically below Options.MinStatus.
+//
+// The threshold follows the severity order of the LogStatus constants (TRACE < INFO < WARN < ERROR < FATAL).
+func (l *Logger) minStatusStage(c Container) (Container, bool) {
+	l.minStatusMu.RLock()
+	defer l.minStatusMu.RUnlock()
+	return c, c.Status >= l.Options.MinStatus
+}
+
+// Changes the threshold below which entries are skipped while the logger is running.
+//
+// This allows switching a service into verbose mode during an incident and back afterwards. Entries which
+// are already queued are filtered with the new threshold. Use SetMinStatus instead of assigning
+// Options.MinStatus, which is not safe while the logger is running.
+//
+// Parameters:
+//   - status: LogStatus - the new threshold e.g. STATUS_TRACE to keep all entries
+//
+// Example:
+//
+//	appLogger.SetMinStatus(logger.STATUS_TRACE) // incident: log everything
+//	appLogger.SetMinStatus(logger.STATUS_WARN)  // back to normal
+func (l *Logger) SetMinStatus(status LogStatus) {
+	l.minStatusMu.Lock()
+	defer l.minStatusMu.Unlock()
+	l.Options.MinStatus = status
+}
+
+// Skips entries for which Options.Filter returns false. Passes all entries if no filter is set.
+func (l *Logger) filterStage(c Container) (Container, bool) {
+	if l.Options.Filter == nil {
+		return c, true
+	}
+	return c, l.Options.Filter(c)
+}
+
+// Increments the status counters, so the counters reflect the true totals even if the entry
+// is sampled out, dropped by a custom stage or absorbed into an aggregate.
+func (l *Logger) countStage(c Container) (Container, bool) {
+	if logStatustoString[c.Status] != "" {
+		// Increment the log level counter
+		incrementLogStatusCounter(l, c.Status)
+		trackWorstStatus(l, c.Status)
+	}
+	return c, true
+}
+
+// Drops entries according to the configured sample rates.
+func (l *Logger) sampleStage(c Container) (Container, bool) {
+	return c, l.sample(c.Status)
+}
+
+// Suppresses entries according to the configured rate limits.
+func (l *Logger) rateLimitStage(c Container) (Container, bool) {
+	return c, l.rateLimit(&c)
+}
+
+// Masks sensitive values in the data field.
+func (l *Logger) redactStage(c Container) (Container, bool) {
+	c.Data = redactData(l.dataRedactor, c.Data)
+	return c, true
+}
+
+// Suppresses repeats of the previous line.
+func (l *Logger) repeatStage(c Container) (Container, bool) {
+	return c, l.suppressRepeat(&c)
+}
+
+// Absorbs entries into an aggregate if aggregation is enabled.
+func (l *Logger) aggregateStage(c Container) (Container, bool) {
+	if !l.aggregationEnabled() {
+		return c, true
+	}
+
+	l.aggregate(c)
+	return c, false
+}
+
+// Decides whether an entry with the given status passes the configured sampling.
+//
+// With a sample rate of N for the status, the first entry and every N-th entry after it pass.
+// Statuses without a sample rate (or a rate below 2) always pass, just like ERROR and FATAL entries,
+// which are never sampled out.
+//
+// Parameters:
+//   - status: LogStatus - the status of the log entry
+//
+// Returns:
+//   - bool: true if the entry shall be emitted, false if it is sampled out
+func (l *Logger) sample(status LogStatus) bool {
+	rate := l.Options.SampleRates[status]
+	if rate < 2 || status >= STATUS_ERROR {
+		return true
+	}
+
+	if l.sampleSeen == nil {
+		l.sampleSeen = make(map[LogStatus]int)
+	}
+
+	seen := l.sampleSeen[status]
+	l.sampleSeen[status]++
+
+	if seen%rate != 0 {
+		l.sampledOut++
+		return false
+	}
+	return true
+}
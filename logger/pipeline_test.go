@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestPipelineCustomStages(t *testing.T) {
+	var seen []string
+
+	l := &Logger{
+		Format:         []LogFormat{FORMAT_INFO},
+		StatusCounters: make(map[LogStatus]int),
+		Options: Options{
+			Pipeline: []Stage{
+				func(c Container) (Container, bool) {
+					return c, c.Source != "health"
+				},
+				func(c Container) (Container, bool) {
+					c.Info = "[" + c.Info + "]"
+					seen = append(seen, c.Info)
+					return c, true
+				},
+			},
+		},
+	}
+
+	l.processEntry(Container{Status: STATUS_INFO, Source: "health", Info: "ping"})
+	l.processEntry(Container{Status: STATUS_INFO, Source: "user", Info: "created"})
+
+	expected := []string{"[created]"}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, seen)
+	}
+
+	// Entries dropped by a custom stage are still counted
+	if count := l.StatusCounters[STATUS_INFO]; count != 2 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 2, count)
+	}
+}
+
+func TestMinStatus(t *testing.T) {
+	var written []string
+
+	l := &Logger{
+		Format:         []LogFormat{FORMAT_INFO},
+		StatusCounters: make(map[LogStatus]int),
+		Options: Options{
+			MinStatus: STATUS_ERROR,
+			Pipeline: []Stage{
+				func(c Container) (Container, bool) {
+					written = append(written, c.Info)
+					return c, true
+				},
+			},
+		},
+	}
+
+	l.processEntry(Container{Status: STATUS_INFO, Info: "info"})
+	l.processEntry(Container{Status: STATUS_WARN, Info: "warn"})
+	l.processEntry(Container{Status: STATUS_ERROR, Info: "error"})
+	l.processEntry(Container{Status: STATUS_FATAL, Info: "fatal"})
+
+	expected := []string{"error", "fatal"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, written)
+	}
+
+	// Skipped entries are not counted
+	expectedCounters := map[LogStatus]int{STATUS_ERROR: 1, STATUS_FATAL: 1}
+	if !reflect.DeepEqual(l.StatusCounters, expectedCounters) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expectedCounters, l.StatusCounters)
+	}
+}
+
+func TestSetMinStatus(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+		MinStatus:      STATUS_WARN,
+	}, Container{Status: STATUS_WARN, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	l.Info("skipped")
+	l.Flush()
+	l.SetMinStatus(STATUS_TRACE)
+	l.Trace("verbose")
+	l.Flush()
+	l.SetMinStatus(STATUS_ERROR)
+	l.Warn("skipped")
+	l.Close()
+
+	expected := "started\nverbose\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var written []string
+
+	l := &Logger{
+		Format:         []LogFormat{FORMAT_INFO},
+		StatusCounters: make(map[LogStatus]int),
+		Options: Options{
+			Filter: func(c Container) bool {
+				return c.Source != "handler/health" || c.Fields["debug"] == true
+			},
+			Pipeline: []Stage{
+				func(c Container) (Container, bool) {
+					written = append(written, c.Info)
+					return c, true
+				},
+			},
+		},
+	}
+
+	l.processEntry(Container{Status: STATUS_INFO, Source: "handler/health", Info: "ping"})
+	l.processEntry(Container{Status: STATUS_INFO, Source: "handler/health", Info: "debug ping", Fields: map[string]any{"debug": true}})
+	l.processEntry(Container{Status: STATUS_WARN, Source: "handler/user", Info: "user"})
+
+	expected := []string{"debug ping", "user"}
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, written)
+	}
+
+	// Filtered entries are not counted
+	expectedCounters := map[LogStatus]int{STATUS_INFO: 1, STATUS_WARN: 1}
+	if !reflect.DeepEqual(l.StatusCounters, expectedCounters) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expectedCounters, l.StatusCounters)
+	}
+}
+
+func TestSamplingKeepsCountersAndSevereEntries(t *testing.T) {
+	written := map[LogStatus]int{}
+
+	l := &Logger{
+		StatusCounters: make(map[LogStatus]int),
+		Options: Options{
+			SampleRates: map[LogStatus]int{STATUS_INFO: 10, STATUS_ERROR: 10, STATUS_FATAL: 10},
+			Pipeline: []Stage{
+				func(c Container) (Container, bool) {
+					written[c.Status]++
+					return c, false
+				},
+			},
+		},
+	}
+
+	for i := 0; i < 1000; i++ {
+		for _, status := range []LogStatus{STATUS_INFO, STATUS_ERROR, STATUS_FATAL} {
+			l.processEntry(Container{Status: status})
+		}
+	}
+
+	// Roughly 1 of every 10 INFO entries passes, ERROR and FATAL always pass
+	expected := map[LogStatus]int{STATUS_INFO: 100, STATUS_ERROR: 1000, STATUS_FATAL: 1000}
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, written)
+	}
+
+	// The counters include the sampled out entries
+	expected = map[LogStatus]int{STATUS_INFO: 1000, STATUS_ERROR: 1000, STATUS_FATAL: 1000}
+	if !reflect.DeepEqual(l.StatusCounters, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, l.StatusCounters)
+	}
+}
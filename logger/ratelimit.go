@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window of the rate limits, pending suppression notices are written at least once per window
+const rateLimitWindow = time.Second
+
+// Token bucket which limits the entries of one status
+type rateLimiter struct {
+	tokens     float64   // Entries which may currently pass
+	last       time.Time // Point in time of the last refill
+	suppressed int       // Entries dropped since the last suppression notice
+}
+
+// Decides whether an entry passes the configured rate limit of its status.
+//
+// Every status with a limit of N entries per second has a token bucket holding at most N tokens,
+// which is refilled continuously at N tokens per second (based on the entry timestamps). An entry
+// consumes one token; if the bucket is empty, the entry is suppressed. The next entry of that status
+// which passes is preceded by a synthetic entry like "12 WARN messages suppressed", so the suppression
+// stays visible. If no entry passes, processLogs writes the pending notices when the window of one
+// second rolls over, and on Close.
+//
+// Parameters:
+//   - c: *Container - the log entry container
+//
+// Returns:
+//   - bool: true if the entry shall be emitted, false if it is suppressed
+func (l *Logger) rateLimit(c *Container) bool {
+	limit := l.Options.RateLimits[c.Status]
+	if limit <= 0 {
+		return true
+	}
+
+	if l.rateLimiters == nil {
+		l.rateLimiters = make(map[LogStatus]*rateLimiter)
+	}
+
+	rl, ok := l.rateLimiters[c.Status]
+	if !ok {
+		rl = &rateLimiter{tokens: float64(limit), last: c.Timestamp}
+		l.rateLimiters[c.Status] = rl
+	}
+
+	// Refill the bucket for the time elapsed since the last entry
+	if elapsed := c.Timestamp.Sub(rl.last); elapsed > 0 {
+		rl.tokens += elapsed.Seconds() * float64(limit)
+		if rl.tokens > float64(limit) {
+			rl.tokens = float64(limit)
+		}
+		rl.last = c.Timestamp
+	}
+
+	if rl.tokens < 1 {
+		rl.suppressed++
+		return false
+	}
+	rl.tokens--
+
+	l.writeSuppressionNotice(c.Status, rl, c.Timestamp)
+	return true
+}
+
+// Writes a synthetic entry telling how many entries of a status were suppressed, if any.
+//
+// Parameters:
+//   - status: LogStatus - the status of the suppressed entries
+//   - rl: *rateLimiter - the limiter of the status
+//   - timestamp: time.Time - the timestamp of the notice
+func (l *Logger) writeSuppressionNotice(status LogStatus, rl *rateLimiter, timestamp time.Time) {
+	if rl.suppressed == 0 {
+		return
+	}
+
+	l.writeEntry(Container{
+		Status:    status,
+		Info:      fmt.Sprintf("%d %s messages suppressed", rl.suppressed, status),
+		Timestamp: timestamp,
+	})
+	rl.suppressed = 0
+}
+
+// Writes the pending suppression notices of all statuses, in order of the statuses.
+//
+// Called by processLogs once per rateLimitWindow and on Close.
+func (l *Logger) flushSuppressionNotices() {
+	for status := STATUS_TRACE; status <= STATUS_FATAL; status++ {
+		if rl, ok := l.rateLimiters[status]; ok {
+			l.writeSuppressionNotice(status, rl, generateTimestamp())
+		}
+	}
+}
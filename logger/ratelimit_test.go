@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRateLimits(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l := &Logger{
+		Format:         []LogFormat{FORMAT_STATUS, FORMAT_INFO},
+		StatusCounters: make(map[LogStatus]int),
+		Options: Options{
+			OutputToStdout: true,
+			Stdout:         &capturedOutput,
+			RateLimits:     map[LogStatus]int{STATUS_WARN: 2},
+		},
+	}
+
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		l.processEntry(Container{Status: STATUS_WARN, Info: "slow", Timestamp: ts})
+		l.processEntry(Container{Status: STATUS_INFO, Info: "ok", Timestamp: ts})
+	}
+
+	// One second later the bucket is full again
+	l.processEntry(Container{Status: STATUS_WARN, Info: "slow again", Timestamp: ts.Add(time.Second)})
+
+	expected := "WARN slow\nINFO ok\nWARN slow\nINFO ok\nINFO ok\nINFO ok\nINFO ok\n" +
+		"WARN 3 WARN messages suppressed\nWARN slow again\n"
+	if actual := capturedOutput.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Suppressed entries are counted
+	if count := l.StatusCounters[STATUS_WARN]; count != 6 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 6, count)
+	}
+}
+
+func TestRateLimitsReportSuppressionOnClose(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l := &Logger{
+		Format:         []LogFormat{FORMAT_INFO},
+		StatusCounters: make(map[LogStatus]int),
+		Options: Options{
+			OutputToStdout: true,
+			Stdout:         &capturedOutput,
+			RateLimits:     map[LogStatus]int{STATUS_ERROR: 1},
+		},
+	}
+
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		l.processEntry(Container{Status: STATUS_ERROR, Info: "failed", Timestamp: ts})
+	}
+	l.flushSuppressionNotices()
+
+	expected := "failed\n2 ERROR messages suppressed\n"
+	if actual := capturedOutput.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestRateLimitsReportSuppressionWithoutFurtherEntries(t *testing.T) {
+	written := make(chan string, 10)
+
+	l, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		RateLimits: map[LogStatus]int{STATUS_ERROR: 1},
+		Hooks:      []func(Container){func(c Container) { written <- c.Info }},
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	defer l.Close()
+
+	// A burst followed by silence
+	for i := 0; i < 3; i++ {
+		l.Entry(Container{Status: STATUS_ERROR, Info: "failed"})
+	}
+
+	for _, expected := range []string{"failed", "2 ERROR messages suppressed"} {
+		select {
+		case actual := <-written:
+			if actual != expected {
+				t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+			}
+		case <-time.After(3 * rateLimitWindow):
+			t.Fatalf("Unexpected result: %#v was not written before Close", expected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import "sync"
+
+// Fixed-size ring of the most recently written lines, see Options.RecentEntries
+type recentRing struct {
+	mu    sync.Mutex // Guards lines and next, the ring is written by processLogs and read by Recent
+	lines []string   // Stored lines, grows up to the capacity
+	next  int        // Index which is overwritten next once the ring is full
+}
+
+// Stores a line, replacing the oldest line if the ring is full.
+//
+// Parameters:
+//   - line: string - the formatted line
+//   - capacity: int - the maximum number of lines
+func (r *recentRing) add(line string, capacity int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.lines) < capacity {
+		r.lines = append(r.lines, line)
+		return
+	}
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % capacity
+}
+
+// Returns the most recently written lines, oldest first.
+//
+// The lines are kept in memory if Options.RecentEntries is set, e.g. to show the recent activity on a
+// /debug/logs endpoint without reading the log files back from disk. The lines are formatted like the
+// main output (see Options.OutputFormat). Recent is safe to call while the logger is running.
+//
+// Returns:
+//   - []string: a copy of at most Options.RecentEntries lines, nil if the option is not set
+//
+// Example:
+//
+//	http.HandleFunc("/debug/logs", func(w http.ResponseWriter, r *http.Request) {
+//	    fmt.Fprintln(w, strings.Join(appLogger.Recent(), "\n"))
+//	})
+func (l *Logger) Recent() []string {
+	l.recent.mu.Lock()
+	defer l.recent.mu.Unlock()
+
+	if len(l.recent.lines) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(l.recent.lines))
+	lines = append(lines, l.recent.lines[l.recent.next:]...)
+	lines = append(lines, l.recent.lines[:l.recent.next]...)
+	return lines
+}
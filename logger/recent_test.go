@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRecent(t *testing.T) {
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{RecentEntries: 3}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	l.Flush()
+	expected := []string{"INFO started"}
+	if actual := l.Recent(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Read the ring while it is written
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			l.Recent()
+		}
+	}()
+	for i := 1; i <= 5; i++ {
+		l.Info(fmt.Sprintf("entry %d", i))
+	}
+	wg.Wait()
+	l.Close()
+
+	// Only the last entries are kept, oldest first
+	expected = []string{"INFO entry 3", "INFO entry 4", "INFO entry 5"}
+	if actual := l.Recent(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Without the option nothing is kept
+	if actual := (&Logger{}).Recent(); actual != nil {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", nil, actual)
+	}
+}
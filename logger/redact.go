@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Replacement of redacted values
+const redactedValue = "***"
+
+// Builds the expression which finds the values of the given keys in key/value or JSON-ish text.
+//
+// It matches "key=value", "key: value", "\"key\": \"value\"" and "'key'='value'" (case-insensitive).
+// Unquoted values end at whitespace, ',', ';', '&' or '}'.
+//
+// Parameters:
+//   - keys: []string - the sensitive keys
+//
+// Returns:
+//   - *regexp.Regexp: the expression, or nil if no key is given
+func dataRedactor(keys []string) *regexp.Regexp {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+
+	return regexp.MustCompile(`(?i)(["']?\b(?:` + strings.Join(quoted, "|") + `)\b["']?\s*[:=]\s*)("(?:[^"\\]|\\.)*"|'[^']*'|[^\s,;&}]+)`)
+}
+
+// Masks the values of sensitive keys in a key/value or JSON-ish string.
+//
+// The redaction is best-effort: strings which do not look like key/value pairs are returned unchanged.
+// Quotes around a value are kept, so JSON stays valid.
+//
+// Parameters:
+//   - re: *regexp.Regexp - the expression built by dataRedactor
+//   - data: string - the string to redact
+//
+// Returns:
+//   - string: the redacted string
+//
+// Example:
+//
+//	re := dataRedactor([]string{"password", "token"})
+//	result := redactData(re, `user=john password=hunter2 {"token": "abc"}`)
+//	// result will be `user=john password=*** {"token": "***"}`
+func redactData(re *regexp.Regexp, data string) string {
+	if re == nil || data == "" {
+		return data
+	}
+
+	return re.ReplaceAllStringFunc(data, func(match string) string {
+		groups := re.FindStringSubmatch(match)
+		value := groups[2]
+
+		switch value[0] {
+		case '"', '\'':
+			return groups[1] + string(value[0]) + redactedValue + string(value[0])
+		}
+		return groups[1] + redactedValue
+	})
+}
+
+// Reports whether the value of a key in structured data must be masked.
+//
+// The key is compared case-insensitively with Options.RedactKeys, so "authorization" also matches
+// the "Authorization" header.
+//
+// Parameters:
+//   - key: string - the map key or field name
+//
+// Returns:
+//   - bool: true if the value must be replaced by "***"
+func (l *Logger) isRedactedKey(key string) bool {
+	for _, redacted := range l.Options.RedactKeys {
+		if strings.EqualFold(key, redacted) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a copy of the fields with the values of sensitive keys masked.
+//
+// Parameters:
+//   - fields: map[string]any - the fields to redact
+//
+// Returns:
+//   - map[string]any: the redacted fields, or the given fields if nothing must be masked
+func (l *Logger) redactFields(fields map[string]any) map[string]any {
+	if len(l.Options.RedactKeys) == 0 {
+		return fields
+	}
+
+	redacted := make(map[string]any, len(fields))
+	for key, value := range fields {
+		if l.isRedactedKey(key) {
+			value = redactedValue
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tracks the last written line to suppress repeats, see Options.RepeatWindow
+type repeatState struct {
+	key     string    // Formatted line of the last written entry without the timestamp
+	status  LogStatus // Status of the last written entry
+	started time.Time // Start of the current window
+	count   int       // Repeats suppressed in the current window
+}
+
+// Decides whether an entry is a repeat of the previous entry which shall be suppressed.
+//
+// Entries are identical if their formatted text line without the timestamp is equal. The first entry
+// is written, identical entries following it are suppressed and counted. The count is written like
+// syslog does as "last message repeated N times" before the next different entry, once the window has
+// elapsed (the next repeat then starts a new window), by processLogs once per window if no further entry
+// arrives (see expireRepeatWindow) and on Close. Does nothing if Options.RepeatWindow is 0.
+//
+// Parameters:
+//   - c: *Container - the log entry container
+//
+// Returns:
+//   - bool: true if the entry shall be emitted, false if it is suppressed
+func (l *Logger) suppressRepeat(c *Container) bool {
+	if l.Options.RepeatWindow <= 0 {
+		return true
+	}
+
+	key := l.formatText(c, l.formatWithout(FORMAT_TIMESTAMP), false)
+
+	if l.repeat != nil && l.repeat.key == key {
+		if c.Timestamp.Sub(l.repeat.started) < l.Options.RepeatWindow {
+			l.repeat.count++
+			return false
+		}
+
+		// The window elapsed, report the repeats so far and keep suppressing in a new window
+		l.writeRepeatNotice(c.Timestamp)
+		l.repeat.started = c.Timestamp
+		l.repeat.count = 1
+		return false
+	}
+
+	l.writeRepeatNotice(c.Timestamp)
+	l.repeat = &repeatState{key: key, status: c.Status, started: c.Timestamp}
+	return true
+}
+
+// Writes a synthetic entry telling how often the last entry was repeated, if it was.
+//
+// Parameters:
+//   - timestamp: time.Time - the timestamp of the notice
+func (l *Logger) writeRepeatNotice(timestamp time.Time) {
+	if l.repeat == nil || l.repeat.count == 0 {
+		return
+	}
+
+	l.writeEntry(Container{
+		Status:    l.repeat.status,
+		Info:      fmt.Sprintf("last message repeated %d times", l.repeat.count),
+		Timestamp: timestamp,
+	})
+	l.repeat.count = 0
+}
+
+// Writes the pending repeat notice and starts a new window, called by processLogs once per Options.RepeatWindow.
+//
+// This reports repeats which are followed by silence. The last line is still remembered, so further
+// repeats are suppressed and counted in the new window.
+//
+// Parameters:
+//   - now: time.Time - the current point in time, used as timestamp of the notice and start of the new window
+func (l *Logger) expireRepeatWindow(now time.Time) {
+	if l.repeat == nil || l.repeat.count == 0 {
+		return
+	}
+
+	l.writeRepeatNotice(now)
+	l.repeat.started = now
+}
+
+// Returns the format of the logger without the given item.
+//
+// Parameters:
+//   - formatItem: LogFormat - the item to leave out
+//
+// Returns:
+//   - []LogFormat: a copy of the format without the item
+func (l *Logger) formatWithout(formatItem LogFormat) []LogFormat {
+	format := l.format()
+	result := make([]LogFormat, 0, len(format))
+	for _, item := range format {
+		if item != formatItem {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRepeatWindow(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l := &Logger{
+		Format:         []LogFormat{FORMAT_TIMESTAMP, FORMAT_STATUS, FORMAT_INFO},
+		StatusCounters: make(map[LogStatus]int),
+		Options: Options{
+			OutputToStdout:  true,
+			Stdout:          &capturedOutput,
+			TimestampLayout: "15:04:05",
+			RepeatWindow:    10 * time.Second,
+		},
+	}
+
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		// The timestamp differs, but is not part of the identity
+		l.processEntry(Container{Status: STATUS_ERROR, Info: "connection refused", Timestamp: ts.Add(time.Duration(i) * time.Second)})
+	}
+	l.processEntry(Container{Status: STATUS_INFO, Info: "reconnected", Timestamp: ts.Add(5 * time.Second)})
+
+	// After the window has elapsed the repeats are reported, even if the run continues
+	for i := 0; i < 3; i++ {
+		l.processEntry(Container{Status: STATUS_INFO, Info: "reconnected", Timestamp: ts.Add(time.Duration(10+i*5) * time.Second)})
+	}
+	l.writeRepeatNotice(ts.Add(30 * time.Second))
+
+	expected := "12:00:00 ERROR connection refused\n" +
+		"12:00:05 ERROR last message repeated 3 times\n" +
+		"12:00:05 INFO reconnected\n" +
+		"12:00:15 INFO last message repeated 1 times\n" +
+		"12:00:30 INFO last message repeated 2 times\n"
+	if actual := capturedOutput.String(); actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+
+	// Suppressed entries are counted
+	if count := l.StatusCounters[STATUS_ERROR]; count != 4 {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", 4, count)
+	}
+}
+
+func TestRepeatWindowReportsWithoutFurtherEntries(t *testing.T) {
+	written := make(chan string, 10)
+
+	l, err := NewLogger([]LogFormat{FORMAT_INFO}, Options{
+		RepeatWindow: 50 * time.Millisecond,
+		Hooks:        []func(Container){func(c Container) { written <- c.Info }},
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	defer l.Close()
+
+	// Repeats followed by silence
+	for i := 0; i < 3; i++ {
+		l.Entry(Container{Status: STATUS_ERROR, Info: "connection refused"})
+	}
+
+	for _, expected := range []string{"connection refused", "last message repeated 2 times"} {
+		select {
+		case actual := <-written:
+			if actual != expected {
+				t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Unexpected result: %#v was not written before Close", expected)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package logger
+
+// A logger which merges base fields into every entry, e.g. the PreText and Source of a service.
+//
+// Create it with Logger.With. A ScopedLogger is safe for concurrent use and cheap to create, so a
+// scope can be derived per request.
+type ScopedLogger struct {
+	logger *Logger
+	base   Container
+}
+
+// Returns a logger which merges the given base fields into every entry.
+//
+// Fields set on the entry override the base fields, see ScopedLogger.Entry.
+//
+// Parameters:
+//   - base: Container - the fields which every entry inherits e.g. PreText and Source
+//
+// Returns:
+//   - *ScopedLogger: the scoped logger writing to l
+//
+// Example:
+//
+//	userLogger := appLogger.With(logger.Container{PreText: "SERVER1", Source: "handler/user"})
+//	userLogger.Entry(logger.Container{Status: logger.STATUS_INFO, Info: "user created"})
+//	// logged with PreText "SERVER1" and Source "handler/user"
+func (l *Logger) With(base Container) *ScopedLogger {
+	return &ScopedLogger{logger: l, base: base}
+}
+
+// Returns a logger which merges the given fields on top of the base fields of s.
+//
+// This allows stacking scopes, e.g. a request scope on top of a service scope.
+//
+// Parameters:
+//   - base: Container - the additional fields, overriding the base fields of s
+//
+// Returns:
+//   - *ScopedLogger: the nested scoped logger
+func (s *ScopedLogger) With(base Container) *ScopedLogger {
+	return &ScopedLogger{logger: s.logger, base: mergeContainer(s.base, base)}
+}
+
+// Logs the entry merged with the base fields, see Logger.Entry.
+//
+// Every field which is set (non-empty, non-zero or non-nil) on the entry overrides the base field.
+// The status is always taken from the entry, since STATUS_TRACE is the zero value. Fields maps are
+// merged key by key, keys of the entry override keys of the base.
+//
+// Parameters:
+//   - c: Container - the log entry container
+func (s *ScopedLogger) Entry(c Container) {
+	c = mergeContainer(s.base, c)
+	s.logger.entry(c, 1)
+}
+
+// Merges the fields of an entry on top of base fields.
+//
+// Parameters:
+//   - base: Container - the base fields
+//   - c: Container - the entry, its status and all of its set fields are kept
+//
+// Returns:
+//   - Container: the merged container
+func mergeContainer(base, c Container) Container {
+	merged := base
+	merged.Status = c.Status
+	merged.flushed = nil
+
+	setString := func(dst *string, value string) {
+		if value != "" {
+			*dst = value
+		}
+	}
+	setString(&merged.PreText, c.PreText)
+	setString(&merged.Id, c.Id)
+	setString(&merged.Source, c.Source)
+	setString(&merged.Info, c.Info)
+	setString(&merged.Data, c.Data)
+	setString(&merged.Error, c.Error)
+	setString(&merged.Caller, c.Caller)
+	setString(&merged.HttpBody, c.HttpBody)
+
+	if c.Err != nil {
+		merged.Err = c.Err
+	}
+	if c.Errors != nil {
+		merged.Errors = c.Errors
+	}
+	if c.ProcessingTime != 0 {
+		merged.ProcessingTime = c.ProcessingTime
+	}
+	if !c.Timestamp.IsZero() {
+		merged.Timestamp = c.Timestamp
+	}
+	if c.HttpRequest != nil {
+		merged.HttpRequest = c.HttpRequest
+	}
+	if c.ProcessedData != nil {
+		merged.ProcessedData = c.ProcessedData
+	}
+	if c.HttpStatus != 0 {
+		merged.HttpStatus = c.HttpStatus
+	}
+	if c.ResponseBytes != 0 {
+		merged.ResponseBytes = c.ResponseBytes
+	}
+
+	// Copy the fields, the base map is shared by all entries of the scope
+	if len(c.Fields) > 0 {
+		merged.Fields = make(map[string]any, len(base.Fields)+len(c.Fields))
+		for key, value := range base.Fields {
+			merged.Fields[key] = value
+		}
+		for key, value := range c.Fields {
+			merged.Fields[key] = value
+		}
+	}
+
+	return merged
+}
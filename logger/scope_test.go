@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScopedLogger(t *testing.T) {
+	l, buf := NewTestLogger([]LogFormat{FORMAT_STATUS, FORMAT_PRE_TEXT, FORMAT_SOURCE, FORMAT_INFO})
+	defer l.Close()
+
+	service := l.With(Container{PreText: "SERVER1", Source: "handler/user"})
+	service.Entry(Container{Status: STATUS_INFO, Info: "user created"})
+	service.Entry(Container{Status: STATUS_WARN, Source: "handler/auth", Info: "token expired"})
+
+	// Scopes can be stacked
+	service.With(Container{PreText: "SERVER2"}).Entry(Container{Status: STATUS_ERROR, Info: "user not found"})
+
+	expected := "INFO SERVER1 handler/user user created\n" +
+		"WARN SERVER1 handler/auth token expired\n" +
+		"ERROR SERVER2 handler/user user not found\n"
+	actual := buf.String()
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestScopedLoggerCaller(t *testing.T) {
+	l, buf := NewTestLogger([]LogFormat{FORMAT_CALLER})
+	defer l.Close()
+
+	// The caller is the code using the scoped logger, not the scope itself
+	l.With(Container{}).Entry(Container{Info: "user created"})
+
+	if actual := buf.String(); !strings.HasPrefix(actual, "scope_test.go:") {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "scope_test.go:...", actual)
+	}
+}
+
+func TestMergeContainerFields(t *testing.T) {
+	base := Container{Fields: map[string]any{"service": "users", "version": 1}}
+
+	merged := mergeContainer(base, Container{Fields: map[string]any{"version": 2, "user_id": 42}})
+
+	expected := map[string]any{"service": "users", "version": 2, "user_id": 42}
+	if !reflect.DeepEqual(merged.Fields, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, merged.Fields)
+	}
+
+	// The base map is left untouched
+	if base.Fields["version"] != 1 || len(base.Fields) != 2 {
+		t.Errorf("Unexpected result: base fields modified: %#v", base.Fields)
+	}
+}
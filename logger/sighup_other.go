@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package logger
+
+// SIGHUP does not exist on this platform, so no handler is installed.
+// Call Rotate directly to reopen the log file.
+func (l *Logger) reopenOnSIGHUP() {}
+
+// No handler is installed on this platform.
+func (l *Logger) stopSIGHUP() {}
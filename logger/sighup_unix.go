@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Installs a process-wide SIGHUP handler which reopens the log file via Rotate.
+//
+// This is the Unix convention for cooperating with external tools like logrotate, which move
+// the log file away and send SIGHUP so the application starts writing into a fresh file.
+func (l *Logger) reopenOnSIGHUP() {
+	l.sighup = make(chan os.Signal, 1)
+	signal.Notify(l.sighup, syscall.SIGHUP)
+
+	go func() {
+		for range l.sighup {
+			if err := l.Rotate(); err != nil {
+				l.reportError("reopen log file", err)
+			}
+		}
+	}()
+}
+
+// Removes the SIGHUP handler, if one was installed.
+func (l *Logger) stopSIGHUP() {
+	if l.sighup != nil {
+		signal.Stop(l.sighup)
+		close(l.sighup)
+	}
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Implements slog.Handler on top of a Logger, see NewSlogHandler
+type slogHandler struct {
+	l      *Logger
+	fields map[string]any // Attributes added by WithAttrs, keyed by their qualified name
+	prefix string         // Qualified name of the open groups followed by a dot e.g. "request.", empty without groups
+}
+
+// Returns a slog.Handler which writes the records through the logger.
+//
+// This allows using log/slog as logging API while the logger provides the formatting and the outputs.
+// The message of a record becomes Container.Info, its attributes become Container.Fields (use FORMAT_FIELDS
+// to log them) and the level is mapped to the status: DEBUG→TRACE, INFO→INFO, WARN→WARN and ERROR→ERROR.
+// Levels in between are mapped to the next lower status. Attributes in groups are flattened into
+// dot-separated keys e.g. "request.method". If the format contains FORMAT_CALLER, the source location of
+// the record is logged.
+//
+// Parameters:
+//   - l: *Logger - the logger which writes the records
+//
+// Returns:
+//   - slog.Handler: the handler
+//
+// Example:
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(appLogger)))
+//	slog.Info("user created", "id", 42) // INFO user created id=42
+func NewSlogHandler(l *Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// Maps a slog level to the status of the entry.
+//
+// Parameters:
+//   - level: slog.Level - the level of the record
+//
+// Returns:
+//   - LogStatus: the matching status
+func slogLevelToStatus(level slog.Level) LogStatus {
+	switch {
+	case level >= slog.LevelError:
+		return STATUS_ERROR
+	case level >= slog.LevelWarn:
+		return STATUS_WARN
+	case level >= slog.LevelInfo:
+		return STATUS_INFO
+	default:
+		return STATUS_TRACE
+	}
+}
+
+// Reports whether records of the level pass Options.MinStatus of the logger.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	h.l.minStatusMu.RLock()
+	defer h.l.minStatusMu.RUnlock()
+	return slogLevelToStatus(level) >= h.l.Options.MinStatus
+}
+
+// Logs the record as entry.
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make(map[string]any, len(h.fields)+r.NumAttrs())
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.prefix, a)
+		return true
+	})
+	if len(fields) == 0 {
+		fields = nil
+	}
+
+	c := Container{
+		Status:    slogLevelToStatus(r.Level),
+		Info:      r.Message,
+		Fields:    fields,
+		Timestamp: r.Time,
+	}
+	if r.PC != 0 && h.l.hasFormat(FORMAT_CALLER) {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		c.Caller = fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+	}
+
+	h.l.entry(c, 1)
+	return nil
+}
+
+// Returns a handler which adds the attributes to every record.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := make(map[string]any, len(h.fields)+len(attrs))
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	for _, a := range attrs {
+		addSlogAttr(fields, h.prefix, a)
+	}
+
+	return &slogHandler{l: h.l, fields: fields, prefix: h.prefix}
+}
+
+// Returns a handler which qualifies the attributes added later with the group name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{l: h.l, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// Adds an attribute to the fields, following the rules of slog.Handler.
+//
+// Attributes with an empty key are ignored unless they are groups, whose attributes are inlined then.
+// Groups are flattened into dot-separated keys, empty groups are ignored.
+//
+// Parameters:
+//   - fields: map[string]any - the fields of the entry
+//   - prefix: string - the qualified name of the enclosing groups followed by a dot, may be empty
+//   - a: slog.Attr - the attribute
+func addSlogAttr(fields map[string]any, prefix string, a slog.Attr) {
+	value := a.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, member := range value.Group() {
+			addSlogAttr(fields, prefix, member)
+		}
+		return
+	}
+
+	if a.Key == "" {
+		return
+	}
+	fields[strings.TrimPrefix(prefix+a.Key, ".")] = value.Any()
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO, FORMAT_FIELDS}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+		MinStatus:      STATUS_INFO,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	logger := slog.New(NewSlogHandler(l))
+	logger.Debug("skipped")
+	logger.Info("user created", "id", 42)
+	logger.Warn("slow request", slog.Group("request", "method", "GET", slog.Group("", "path", "/users")))
+
+	requestLogger := logger.With("service", "api").WithGroup("request").With("id", "5f322a")
+	requestLogger.Error("request failed", "status", 500, slog.Group("empty"))
+	l.Close()
+
+	expected := strings.Join([]string{
+		"INFO started",
+		"INFO user created id=42",
+		"WARN slow request request.method=GET request.path=/users",
+		"ERROR request failed request.id=5f322a request.status=500 service=api",
+	}, "\n") + "\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestSlogHandlerCaller(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_CALLER, FORMAT_INFO}, Options{OutputToStdout: true, Stdout: &capturedOutput}, Container{Caller: "-", Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	slog.New(NewSlogHandler(l)).Info("here")
+	l.Close()
+
+	if actual := capturedOutput.String(); !strings.Contains(actual, "slog_test.go:") {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "slog_test.go:<line> here", actual)
+	}
+}
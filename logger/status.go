@@ -7,29 +7,69 @@ import (
 )
 
 // The status which will be displayed in the message e.g. [WARN]
+//
+// The constants are ordered by severity, from the most verbose STATUS_TRACE to STATUS_FATAL.
 type LogStatus int
 
 const (
-	STATUS_INFO LogStatus = iota
+	STATUS_TRACE LogStatus = iota
+	STATUS_INFO
 	STATUS_WARN
-	STATUS_TRACE
 	STATUS_ERROR
 	STATUS_FATAL
 )
 
 var logStatustoString = map[LogStatus]string{
+	STATUS_TRACE: "TRACE",
 	STATUS_INFO:  "INFO",
 	STATUS_WARN:  "WARN",
-	STATUS_TRACE: "TRACE",
 	STATUS_ERROR: "ERROR",
 	STATUS_FATAL: "FATAL",
 }
 
+// Returns the name of the log status e.g. "WARN", implementing fmt.Stringer.
+//
+// Returns:
+//   - string: the name of the status, or "LogStatus(<n>)" for an undefined status
+func (s LogStatus) String() string {
+	if str, ok := logStatustoString[s]; ok {
+		return str
+	}
+	return fmt.Sprintf("LogStatus(%d)", int(s))
+}
+
+// Parses the name of a log status, e.g. a minimum status read from a configuration file or environment variable.
+//
+// The name is matched case-insensitively and surrounding whitespace is ignored.
+//
+// Parameters:
+//   - name: string - the name of the status e.g. "WARN" or "warn"
+//
+// Returns:
+//   - LogStatus: the parsed status
+//   - error: ErrInvalidSeverity if the name does not belong to a status
+//
+// Example:
+//
+//	status, err := logger.ParseLogStatus(os.Getenv("LOG_LEVEL"))
+//	if err != nil {
+//	    status = logger.STATUS_INFO
+//	}
+func ParseLogStatus(name string) (LogStatus, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	for status, str := range logStatustoString {
+		if str == name {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidSeverity, name)
+}
+
 // Increments the log level counter for the given log status.
 //
 // It is a function that takes a Logger instance and a Container pointer as arguments. The function increments
 // the log level counter for the log status specified in the Container. The log level counters are maintained
-// within the Logger instance.
+// within the Logger instance and guarded by a mutex, so they can be read while entries are processed.
 //
 // Example:
 //
@@ -45,6 +85,9 @@ var logStatustoString = map[LogStatus]string{
 //	Log Level Counters:
 //	  INFO: 1
 func incrementLogStatusCounter(l *Logger, ls LogStatus) {
+	l.countersMu.Lock()
+	defer l.countersMu.Unlock()
+
 	l.StatusCounters[ls]++
 }
 
@@ -55,7 +98,7 @@ func incrementLogStatusCounter(l *Logger, ls LogStatus) {
 // count values.
 //
 // The method iterates over the log level counters stored in the `l.StatusCounters` map. It sorts the keys (log levels)
-// in ascending order and retrieves the count value for each log level. The log level names and count values are then
+// in ascending order (from TRACE to FATAL) and retrieves the count value for each log level. The log level names and count values are then
 // formatted and appended to a strings.Builder. The resulting formatted string represents the log level counters.
 //
 // Example:
@@ -64,11 +107,14 @@ func incrementLogStatusCounter(l *Logger, ls LogStatus) {
 //	// Log some entries
 //	counters := logger.GetLogStatusCounters()
 //	fmt.Println(counters)
-//	// Output example: Log Level Counters: [DEBUG: 2] [INFO: 5] [WARNING: 3] [ERROR: 1]
+//	// Output example: Log Level Counters: [TRACE: 2] [INFO: 5] [WARN: 3] [ERROR: 1]
 func (l *Logger) GetLogStatusCounters() string {
 	var builder strings.Builder
 	builder.WriteString("Log Level Counters:")
 
+	l.countersMu.RLock()
+	defer l.countersMu.RUnlock()
+
 	// Sort the keys of the log level counters
 	keys := make([]int, 0, len(l.StatusCounters))
 	for status := range l.StatusCounters {
@@ -84,3 +130,56 @@ func (l *Logger) GetLogStatusCounters() string {
 
 	return builder.String()
 }
+
+// Returns the number of processed log entries per status.
+//
+// It is safe to call concurrently with logging. The returned map is a copy, so it can be kept or modified by the caller (e.g. to export the numbers to a
+// monitoring system or to assert on them in tests) without affecting the logger.
+//
+// Example:
+//
+//	counts := appLogger.StatusCounts()
+//	errorsTotal.Set(float64(counts[logger.STATUS_ERROR]))
+func (l *Logger) StatusCounts() map[LogStatus]int {
+	l.countersMu.RLock()
+	defer l.countersMu.RUnlock()
+
+	counts := make(map[LogStatus]int, len(l.StatusCounters))
+	for status, count := range l.StatusCounters {
+		counts[status] = count
+	}
+	return counts
+}
+
+// Remembers the given log status if it is more severe than every status logged before.
+//
+// Parameters:
+//   - l: *Logger - the logger instance
+//   - ls: LogStatus - the status of the processed log entry
+func trackWorstStatus(l *Logger, ls LogStatus) {
+	for {
+		worst := l.worstStatus.Load()
+		if int32(ls) <= worst || l.worstStatus.CompareAndSwap(worst, int32(ls)) {
+			return
+		}
+	}
+}
+
+// Returns the most severe log status logged so far.
+//
+// Severity follows the numeric order of the LogStatus constants, so a later constant is treated as
+// more severe. If nothing was logged yet, the lowest status STATUS_TRACE is returned. It is safe to call concurrently with logging.
+//
+// A CLI can use it to derive the process exit code at the end of a run.
+//
+// Example:
+//
+//	switch appLogger.WorstStatus() {
+//	case logger.STATUS_FATAL:
+//	    os.Exit(2)
+//	case logger.STATUS_ERROR:
+//	    os.Exit(1)
+//	}
+func (l *Logger) WorstStatus() LogStatus {
+	return LogStatus(l.worstStatus.Load())
+}
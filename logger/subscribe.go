@@ -0,0 +1,88 @@
+package logger
+
+// Number of entries buffered per subscriber before further entries are dropped
+const subscriberBufferSize = 100
+
+// Returns a channel which receives a copy of every written log entry.
+//
+// This allows in-process components to react to log events, e.g. to open a circuit breaker on repeated errors.
+// The channel is buffered; if a subscriber does not keep up, entries are dropped for this subscriber
+// instead of blocking the logger. Call Unsubscribe when the channel is no longer read.
+// The channel is closed by Unsubscribe or when the logger is closed. Subscribing to a closed logger
+// returns an already closed channel, so ranging over it ends immediately.
+//
+// Returns:
+//   - <-chan Container: the channel receiving the log entries
+//
+// Example:
+//
+//	events := appLogger.Subscribe()
+//	defer appLogger.Unsubscribe(events)
+//	for c := range events {
+//	    if c.Status == logger.STATUS_ERROR {
+//	        breaker.Fail()
+//	    }
+//	}
+func (l *Logger) Subscribe() <-chan Container {
+	ch := make(chan Container, subscriberBufferSize)
+
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
+
+	// No entry will be published anymore, so nobody would close the channel later
+	if l.subClosed {
+		close(ch)
+		return ch
+	}
+
+	if l.subscribers == nil {
+		l.subscribers = make(map[<-chan Container]chan Container)
+	}
+	l.subscribers[ch] = ch
+
+	return ch
+}
+
+// Removes a subscription created by Subscribe and closes its channel.
+//
+// Parameters:
+//   - ch: <-chan Container - the channel returned by Subscribe
+func (l *Logger) Unsubscribe(ch <-chan Container) {
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
+
+	if sub, ok := l.subscribers[ch]; ok {
+		delete(l.subscribers, ch)
+		close(sub)
+	}
+}
+
+// Sends a copy of the log entry to every subscriber without blocking.
+//
+// Parameters:
+//   - c: Container - the written log entry
+func (l *Logger) publish(c Container) {
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
+
+	for _, sub := range l.subscribers {
+		select {
+		case sub <- c:
+		default:
+			// The subscriber is too slow, drop the entry for it
+		}
+	}
+}
+
+// Closes the channels of all subscribers and marks the subscriptions as closed.
+func (l *Logger) unsubscribeAll() {
+	l.subMu.Lock()
+	defer l.subMu.Unlock()
+
+	l.subClosed = true
+
+	for ch, sub := range l.subscribers {
+		delete(l.subscribers, ch)
+		close(sub)
+	}
+}
@@ -0,0 +1,60 @@
+package logger
+
+import "testing"
+
+func TestSubscribe(t *testing.T) {
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{}, Container{Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	events := logger.Subscribe()
+	slow := logger.Subscribe()
+	unsubscribed := logger.Subscribe()
+	logger.Unsubscribe(unsubscribed)
+
+	for i := 0; i < subscriberBufferSize+10; i++ {
+		logger.Entry(Container{Status: STATUS_ERROR, Info: "failed"})
+
+		// The start entry may still be in flight when subscribing, so skip it
+		c := <-events
+		for c.Info == "started" {
+			c = <-events
+		}
+		if c.Info != "failed" {
+			t.Fatalf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "failed", c.Info)
+		}
+	}
+
+	logger.Close()
+
+	// The slow subscriber never read, so it only holds the buffered entries
+	count := 0
+	for range slow {
+		count++
+	}
+	if count != subscriberBufferSize {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", subscriberBufferSize, count)
+	}
+
+	if _, ok := <-unsubscribed; ok {
+		t.Errorf("Unexpected result: channel of unsubscribed subscriber is still open")
+	}
+}
+
+func TestSubscribeAfterClose(t *testing.T) {
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+	logger.Close()
+
+	// Ranging over the channel must not block forever
+	events := logger.Subscribe()
+	if _, ok := <-events; ok {
+		t.Errorf("Unexpected result: channel of subscription after Close is open")
+	}
+
+	// Unsubscribing the never registered channel is a no-op
+	logger.Unsubscribe(events)
+}
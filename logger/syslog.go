@@ -0,0 +1,64 @@
+package logger
+
+// Writes messages to the system log with a priority, implemented by *syslog.Writer
+type syslogWriter interface {
+	Debug(m string) error
+	Info(m string) error
+	Warning(m string) error
+	Err(m string) error
+	Crit(m string) error
+	Close() error
+}
+
+// Connects to the syslog daemon configured in the options.
+//
+// If the connection fails (or syslog is not supported on the platform), the error is reported
+// through Options.ErrorHandler and the logger continues without the syslog output.
+func (l *Logger) connectSyslog() {
+	w, err := dialSyslog(l.Options.SyslogNetwork, l.Options.SyslogAddr, l.Options.SyslogTag)
+	if err != nil {
+		l.reportError("connect to syslog", err)
+		return
+	}
+	l.syslog = w
+}
+
+// Writes the log entry to the system log.
+//
+// The message is formatted with the text formatter and written with the priority matching the status:
+// TRACE→LOG_DEBUG, INFO→LOG_INFO, WARN→LOG_WARNING, ERROR→LOG_ERR and FATAL→LOG_CRIT.
+//
+// Parameters:
+//   - c: *Container - the log entry container
+func (l *Logger) writeToSyslog(c *Container) {
+	message := l.formatText(c, l.format(), false)
+
+	var err error
+	switch c.Status {
+	case STATUS_TRACE:
+		err = l.syslog.Debug(message)
+	case STATUS_WARN:
+		err = l.syslog.Warning(message)
+	case STATUS_ERROR:
+		err = l.syslog.Err(message)
+	case STATUS_FATAL:
+		err = l.syslog.Crit(message)
+	default:
+		err = l.syslog.Info(message)
+	}
+
+	if err != nil {
+		l.reportError("write to syslog", err)
+	}
+}
+
+// Closes the connection to the syslog daemon, if any.
+//
+// Returns:
+//   - error: an error if the connection could not be closed
+func (l *Logger) closeSyslog() error {
+	if l.syslog == nil {
+		return nil
+	}
+	return l.syslog.Close()
+}
@@ -0,0 +1,8 @@
+//go:build windows || plan9
+
+package logger
+
+// Syslog is not supported on this platform.
+func dialSyslog(network, addr, tag string) (syslogWriter, error) {
+	return nil, ErrSyslogUnavailable
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Records the messages written to the system log
+type fakeSyslog struct {
+	messages []string
+	closed   bool
+}
+
+func (f *fakeSyslog) record(priority, m string) error {
+	f.messages = append(f.messages, priority+" "+m)
+	return nil
+}
+
+func (f *fakeSyslog) Debug(m string) error   { return f.record("DEBUG", m) }
+func (f *fakeSyslog) Info(m string) error    { return f.record("INFO", m) }
+func (f *fakeSyslog) Warning(m string) error { return f.record("WARNING", m) }
+func (f *fakeSyslog) Err(m string) error     { return f.record("ERR", m) }
+func (f *fakeSyslog) Crit(m string) error    { return f.record("CRIT", m) }
+func (f *fakeSyslog) Close() error           { f.closed = true; return nil }
+
+func TestWriteToSyslog(t *testing.T) {
+	fake := &fakeSyslog{}
+	l := &Logger{
+		Format:  []LogFormat{FORMAT_STATUS, FORMAT_INFO},
+		Options: Options{OutputFormat: OUTPUT_JSON},
+		syslog:  fake,
+	}
+
+	for _, status := range []LogStatus{STATUS_TRACE, STATUS_INFO, STATUS_WARN, STATUS_ERROR, STATUS_FATAL} {
+		l.writeEntry(Container{Status: status, Info: "message"})
+	}
+
+	// The text formatter is used regardless of the output format
+	expected := []string{
+		"DEBUG TRACE message",
+		"INFO INFO message",
+		"WARNING WARN message",
+		"ERR ERROR message",
+		"CRIT FATAL message",
+	}
+	if !reflect.DeepEqual(fake.messages, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, fake.messages)
+	}
+
+	if err := l.closeSyslog(); err != nil || !fake.closed {
+		t.Errorf("Unexpected result: syslog connection was not closed")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows && !plan9
+
+package logger
+
+import "log/syslog"
+
+// Connects to the syslog daemon.
+//
+// Parameters:
+//   - network: string - the network of the daemon e.g. "udp", empty for the local daemon
+//   - addr: string - the address of the daemon, empty for the local daemon
+//   - tag: string - the tag of the messages, empty for the program name
+//
+// Returns:
+//   - syslogWriter: the connection
+//   - error: an error if the daemon cannot be reached
+func dialSyslog(network, addr, tag string) (syslogWriter, error) {
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+}
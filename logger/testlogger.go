@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"bytes"
+)
+
+// Creates a logger which writes the text output synchronously to an in-memory buffer.
+//
+// The logger is meant for tests: it writes nothing to the file system or STDOUT and every entry is
+// formatted and written before Entry returns (see Options.Synchronous), so the buffer can be asserted
+// right after logging without redirecting os.Stdout or sleeping. The buffer starts empty. Like
+// regexp.MustCompile it panics if the format is invalid, since this is a programming error in the test.
+//
+// Parameters:
+//   - format: []LogFormat - the format of the log entries
+//
+// Returns:
+//   - *Logger: the created Logger instance, which should be closed at the end of the test
+//   - *bytes.Buffer: the buffer receiving one line per log entry
+//
+// Example:
+//
+//	l, buf := logger.NewTestLogger([]logger.LogFormat{logger.FORMAT_STATUS, logger.FORMAT_INFO})
+//	defer l.Close()
+//
+//	l.Entry(logger.Container{Status: logger.STATUS_INFO, Info: "user created"})
+//	if buf.String() != "INFO user created\n" {
+//	    t.Errorf("unexpected log output: %q", buf.String())
+//	}
+func NewTestLogger(format []LogFormat) (*Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+
+	l, err := NewLogger(format, Options{OutputToStdout: true, Stdout: buf, Synchronous: true}, Container{})
+	if err != nil {
+		panic("logger: NewTestLogger: " + err.Error())
+	}
+	return l, buf
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"testing"
+)
+
+func TestNewTestLogger(t *testing.T) {
+	l, buf := NewTestLogger([]LogFormat{FORMAT_STATUS, FORMAT_SOURCE, FORMAT_INFO})
+	defer l.Close()
+
+	if buf.Len() != 0 {
+		t.Fatalf("Unexpected result: buffer not empty: %q", buf.String())
+	}
+
+	l.Entry(Container{Status: STATUS_INFO, Source: "handler/user", Info: "user created"})
+	l.Entry(Container{Status: STATUS_WARN, Info: "slow query"})
+
+	// No flush needed, the entries are written before Entry returns
+	expected := "INFO handler/user user created\nWARN slow query\n"
+	actual := buf.String()
+
+	if actual != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}
+
+func TestNewTestLoggerPanicsOnInvalidFormat(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Unexpected result: expected a panic")
+		}
+	}()
+
+	NewTestLogger([]LogFormat{LogFormat(999)})
+}
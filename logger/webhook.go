@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Defaults of the webhook output
+const (
+	defaultWebhookTimeout = 5 * time.Second
+	webhookQueueSize      = 100
+)
+
+// Starts the worker which posts log entries to Options.WebhookURL.
+//
+// The entries are handed over through a bounded queue, so a slow or unreachable endpoint never
+// blocks processLogs. If the queue is full, the entry is discarded and the error is reported.
+func (l *Logger) startWebhook() {
+	timeout := l.Options.WebhookTimeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+
+	l.webhookQueue = make(chan []byte, webhookQueueSize)
+	l.webhookDone = make(chan struct{})
+
+	go func() {
+		defer close(l.webhookDone)
+		for payload := range l.webhookQueue {
+			l.postWebhook(client, payload)
+		}
+	}()
+}
+
+// Posts a single payload to the webhook and reports failures.
+//
+// Parameters:
+//   - client: *http.Client - the client used for the request
+//   - payload: []byte - the JSON encoded log entry
+func (l *Logger) postWebhook(client *http.Client, payload []byte) {
+	resp, err := client.Post(l.Options.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		l.reportError("post log entry to webhook", err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		l.reportError("post log entry to webhook", fmt.Errorf("unexpected status %s", resp.Status))
+	}
+}
+
+// Queues the log entry for the webhook if its status reaches Options.WebhookMinStatus.
+//
+// The entry is always serialized like the OUTPUT_JSON format, using the configured format items, so the
+// payload keeps its shape independent of Options.OutputFormat (e.g. with OUTPUT_NDJSON on STDOUT).
+//
+// Parameters:
+//   - c: *Container - the log entry container
+func (l *Logger) sendToWebhook(c *Container) {
+	if c.Status < l.Options.WebhookMinStatus {
+		return
+	}
+
+	select {
+	case l.webhookQueue <- []byte(l.formatJSON(c, l.format(), OUTPUT_JSON)):
+	default:
+		l.reportError("post log entry to webhook", ErrLogQueueFull)
+	}
+}
+
+// Stops the webhook worker after all queued entries have been posted.
+func (l *Logger) stopWebhook() {
+	if l.webhookQueue == nil {
+		return
+	}
+	close(l.webhookQueue)
+	<-l.webhookDone
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", "application/json", r.Header.Get("Content-Type"))
+		}
+
+		mu.Lock()
+		payloads = append(payloads, string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	logger, err := NewLogger([]LogFormat{FORMAT_TIMESTAMP, FORMAT_STATUS, FORMAT_INFO}, Options{
+		WebhookURL:       server.URL,
+		WebhookMinStatus: STATUS_FATAL,
+		WebhookTimeout:   time.Second,
+	}, Container{Status: STATUS_INFO, Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	logger.Entry(Container{Status: STATUS_ERROR, Info: "retrying", Timestamp: ts})
+	logger.Entry(Container{Status: STATUS_FATAL, Info: "database unreachable", Timestamp: ts})
+
+	// Close waits until the queued payloads are posted
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	expected := []string{`{"timestamp":"2023-06-01T12:00:00Z","status":"FATAL","info":"database unreachable"}`}
+	if !reflect.DeepEqual(payloads, expected) {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, payloads)
+	}
+}
+
+func TestWebhookIgnoresOutputFormat(t *testing.T) {
+	var payload string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payload = string(body)
+	}))
+	defer server.Close()
+
+	// STDOUT uses the NDJSON conventions, the webhook still receives the plain JSON keys
+	logger, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_INFO}, Options{
+		OutputFormat:     OUTPUT_NDJSON,
+		WebhookURL:       server.URL,
+		WebhookMinStatus: STATUS_FATAL,
+	}, Container{})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	logger.Entry(Container{Status: STATUS_FATAL, Info: "database unreachable"})
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	expected := `{"status":"FATAL","info":"database unreachable"}`
+	if payload != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, payload)
+	}
+}
+
+func TestWebhookReportsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var reported []error
+	l := &Logger{
+		Format: []LogFormat{FORMAT_INFO},
+		Options: Options{
+			WebhookURL:   server.URL,
+			ErrorHandler: func(err error) { reported = append(reported, err) },
+		},
+	}
+	l.startWebhook()
+	l.writeEntry(Container{Status: STATUS_FATAL, Info: "failed"})
+	l.stopWebhook()
+
+	expected := "failed to post log entry to webhook: unexpected status 500 Internal Server Error"
+	if len(reported) != 1 || reported[0].Error() != expected {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, reported)
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Adapts the logger to an io.Writer, see Logger.Writer
+type entryWriter struct {
+	l      *Logger
+	status LogStatus
+	source string
+
+	mu      sync.Mutex // Guards pending, Write may be called concurrently
+	pending []byte     // Start of a line which has not been terminated yet
+}
+
+// Returns an io.Writer which logs every line written to it as an entry.
+//
+// Each line (without the line break) becomes the Info of an entry with the given status and source.
+// Partial lines are buffered until the line break is written. This allows capturing the output of
+// libraries which log via the standard log package. The writer is safe for concurrent use.
+//
+// Parameters:
+//   - status: LogStatus - the status of the entries
+//   - source: string - the source of the entries e.g. "stdlib"
+//
+// Returns:
+//   - io.Writer: the adapter
+//
+// Example:
+//
+//	log.SetFlags(0) // the logger adds its own timestamp
+//	log.SetOutput(appLogger.Writer(logger.STATUS_INFO, "stdlib"))
+//	log.Println("captured") // logged as INFO entry with the source "stdlib"
+func (l *Logger) Writer(status LogStatus, source string) io.Writer {
+	return &entryWriter{l: l, status: status, source: source}
+}
+
+// Logs every complete line of p and buffers the rest.
+//
+// Parameters:
+//   - p: []byte - the written data
+//
+// Returns:
+//   - int: always len(p)
+//   - error: always nil
+func (w *entryWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := bytes.TrimSuffix(w.pending[:i], []byte("\r"))
+		w.l.entry(Container{Status: w.status, Source: w.source, Info: string(line)}, 1)
+		w.pending = w.pending[i+1:]
+	}
+
+	// Release the consumed part of the buffer once all lines are logged
+	if len(w.pending) == 0 {
+		w.pending = nil
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var capturedOutput bytes.Buffer
+
+	l, err := NewLogger([]LogFormat{FORMAT_STATUS, FORMAT_SOURCE, FORMAT_INFO}, Options{
+		OutputToStdout: true,
+		Stdout:         &capturedOutput,
+	}, Container{Status: STATUS_INFO, Source: "main", Info: "started"})
+	if err != nil {
+		t.Fatalf("Unexpected result: " + err.Error())
+	}
+
+	stdlib := log.New(l.Writer(STATUS_WARN, "stdlib"), "", 0)
+	stdlib.Println("deprecated option used")
+
+	// Partial lines are buffered until the line break
+	w := l.Writer(STATUS_INFO, "raw")
+	w.Write([]byte("first "))
+	w.Write([]byte("line\r\nsecond line\nthird"))
+	l.Close()
+
+	expected := "INFO main started\nWARN stdlib deprecated option used\nINFO raw first line\nINFO raw second line\n"
+	if actual := capturedOutput.String(); expected != actual {
+		t.Errorf("Unexpected result.\nExpected:\n%#v\nGot:\n%#v", expected, actual)
+	}
+}